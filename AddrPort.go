@@ -0,0 +1,153 @@
+package i2pkeys
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// I2PAddrPort is an I2P destination with a SAM 3.2 port (FROM_PORT and
+// TO_PORT). The destination is known as a full base64 destination, only by
+// its hash, or only by its hostname before it has been looked up.
+// I2PAddrPort implements net.Addr and is comparable; see Key for using it in
+// maps.
+type I2PAddrPort struct {
+	addr    I2PAddr
+	hash    I2PDestHash
+	hasHash bool
+	name    string
+	port    uint16
+}
+
+// NewI2PAddrPort returns the I2PAddrPort of the destination addr and port.
+func NewI2PAddrPort(addr I2PAddr, port uint16) (I2PAddrPort, error) {
+	a, err := ParseAddress(addr)
+	if err != nil {
+		return I2PAddrPort{}, err
+	}
+	return a.WithPort(port), nil
+}
+
+// WithPort returns the destination with port.
+func (a Address) WithPort(port uint16) I2PAddrPort {
+	return I2PAddrPort{addr: a.I2PAddr(), hash: a.DestHash(), hasHash: true, port: port}
+}
+
+// WithPort returns the destination hash with port.
+func (h I2PDestHash) WithPort(port uint16) I2PAddrPort {
+	return I2PAddrPort{hash: h, hasHash: true, port: port}
+}
+
+// ParseI2PAddrPort parses "host:port", where host is a .i2p hostname, a
+// .b32.i2p address or a base64 destination, and port is decimal.
+func ParseI2PAddrPort(s string) (I2PAddrPort, error) {
+	log.WithField("addr", s).Debug("Parsing I2PAddrPort")
+	i := strings.LastIndexByte(s, ':')
+	if i < 0 {
+		return I2PAddrPort{}, fmt.Errorf("missing port in address %q", s)
+	}
+	port, err := strconv.ParseUint(s[i+1:], 10, 16)
+	if err != nil {
+		return I2PAddrPort{}, fmt.Errorf("invalid port in address %q", s)
+	}
+	host := s[:i]
+	lower := strings.ToLower(host)
+	switch {
+	case strings.HasSuffix(lower, ".b32.i2p") && len(lower) == 52+len(".b32.i2p"):
+		h, err := DestHashFromString(lower)
+		if err != nil {
+			return I2PAddrPort{}, err
+		}
+		return h.WithPort(uint16(port)), nil
+	case strings.HasSuffix(lower, ".b32.i2p"):
+		return I2PAddrPort{}, fmt.Errorf("%q is not a .b32.i2p address", host)
+	case strings.HasSuffix(lower, ".i2p"):
+		if !i2pHostnameRe.MatchString(lower) {
+			return I2PAddrPort{}, fmt.Errorf("%q is not an I2P hostname", host)
+		}
+		return I2PAddrPort{name: lower, port: uint16(port)}, nil
+	}
+	return NewI2PAddrPort(I2PAddr(host), uint16(port))
+}
+
+// I2PAddrPortFrom converts a net.Addr of the I2P network, such as the
+// RemoteAddr of a connection, to an I2PAddrPort. I2PAddr, Address,
+// I2PDestHash and I2PKeys convert with port 0, other types are parsed from
+// their String.
+func I2PAddrPortFrom(addr net.Addr) (I2PAddrPort, error) {
+	switch a := addr.(type) {
+	case I2PAddrPort:
+		return a, nil
+	case *I2PAddrPort:
+		if a == nil {
+			return I2PAddrPort{}, errors.New("nil address")
+		}
+		return *a, nil
+	case I2PAddr:
+		return NewI2PAddrPort(a, 0)
+	case Address:
+		return a.WithPort(0), nil
+	case I2PDestHash:
+		return a.WithPort(0), nil
+	case I2PKeys:
+		return NewI2PAddrPort(a.Address, 0)
+	case nil:
+		return I2PAddrPort{}, errors.New("nil address")
+	}
+	if addr.Network() != "I2P" {
+		return I2PAddrPort{}, fmt.Errorf("not an I2P address: network %q", addr.Network())
+	}
+	return ParseI2PAddrPort(addr.String())
+}
+
+// IsZero reports whether ap is the zero I2PAddrPort.
+func (ap I2PAddrPort) IsZero() bool {
+	return ap == I2PAddrPort{}
+}
+
+// Addr returns the full destination, or "" if it is not known.
+func (ap I2PAddrPort) Addr() I2PAddr {
+	return ap.addr
+}
+
+// DestHash returns the destination hash, and false if it is not known.
+func (ap I2PAddrPort) DestHash() (I2PDestHash, bool) {
+	return ap.hash, ap.hasHash
+}
+
+// Hostname returns the .i2p hostname the value was parsed from, or "".
+func (ap I2PAddrPort) Hostname() string {
+	return ap.name
+}
+
+// Port returns the port.
+func (ap I2PAddrPort) Port() uint16 {
+	return ap.port
+}
+
+// Key returns ap reduced to the destination hash and port, so that values
+// for the same destination are equal whether or not they carry the full
+// destination. Hostnames are kept as they are, they need a lookup.
+func (ap I2PAddrPort) Key() I2PAddrPort {
+	if !ap.hasHash {
+		return ap
+	}
+	return ap.hash.WithPort(ap.port)
+}
+
+// String returns "host:port", where host is the .b32.i2p address, or the
+// hostname if the destination is not known.
+func (ap I2PAddrPort) String() string {
+	host := ap.name
+	if ap.hasHash {
+		host = ap.hash.String()
+	}
+	return host + ":" + strconv.Itoa(int(ap.port))
+}
+
+// Network returns "I2P".
+func (ap I2PAddrPort) Network() string {
+	return "I2P"
+}
@@ -0,0 +1,86 @@
+package i2pkeys
+
+import (
+	"net"
+	"testing"
+)
+
+func Test_I2PAddrPort(t *testing.T) {
+	addr := I2PAddr(validI2PAddrB64)
+	b32 := addr.Base32()
+
+	t.Run("Destination with port", func(t *testing.T) {
+		ap, err := NewI2PAddrPort(addr, 8080)
+		if err != nil {
+			t.Fatalf("NewI2PAddrPort failed: %v", err)
+		}
+		var _ net.Addr = ap
+		if ap.Addr() != addr || ap.Port() != 8080 || ap.Network() != "I2P" {
+			t.Errorf("Unexpected I2PAddrPort %v", ap)
+		}
+		if h, ok := ap.DestHash(); !ok || h != addr.DestHash() {
+			t.Error("DestHash does not match the destination")
+		}
+		if ap.String() != b32+":8080" {
+			t.Errorf("String() = %s", ap.String())
+		}
+	})
+
+	t.Run("Parse forms", func(t *testing.T) {
+		for _, s := range []string{b32 + ":80", validI2PAddrB64 + ":80"} {
+			ap, err := ParseI2PAddrPort(s)
+			if err != nil {
+				t.Fatalf("ParseI2PAddrPort(%q) failed: %v", s, err)
+			}
+			if ap.String() != b32+":80" {
+				t.Errorf("String() = %s", ap.String())
+			}
+		}
+		ap, err := ParseI2PAddrPort("Example.i2p:443")
+		if err != nil {
+			t.Fatalf("ParseI2PAddrPort failed: %v", err)
+		}
+		if _, ok := ap.DestHash(); ok || ap.Hostname() != "example.i2p" || ap.String() != "example.i2p:443" {
+			t.Errorf("Unexpected I2PAddrPort %v", ap)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, s := range []string{"example.i2p", "example.i2p:65536", "example.i2p:http", "example.com:80", b32[:10] + ".b32.i2p:80", "AAAA:80"} {
+			if _, err := ParseI2PAddrPort(s); err == nil {
+				t.Errorf("ParseI2PAddrPort(%q) should have failed", s)
+			}
+		}
+	})
+
+	t.Run("Map keys", func(t *testing.T) {
+		full, _ := NewI2PAddrPort(addr, 80)
+		fromB32, _ := ParseI2PAddrPort(b32 + ":80")
+		seen := map[I2PAddrPort]bool{full.Key(): true}
+		if !seen[fromB32.Key()] {
+			t.Error("Key() differs for the same destination and port")
+		}
+		other, _ := ParseI2PAddrPort(b32 + ":81")
+		if seen[other.Key()] {
+			t.Error("Key() equal for different ports")
+		}
+	})
+
+	t.Run("From net.Addr", func(t *testing.T) {
+		for _, a := range []net.Addr{addr, addr.DestHash(), &net.UnixAddr{Name: b32 + ":0", Net: "I2P"}} {
+			ap, err := I2PAddrPortFrom(a)
+			if err != nil {
+				t.Fatalf("I2PAddrPortFrom(%T) failed: %v", a, err)
+			}
+			if ap.String() != b32+":0" {
+				t.Errorf("I2PAddrPortFrom(%T) = %s", a, ap)
+			}
+		}
+		if _, err := I2PAddrPortFrom(&net.TCPAddr{}); err == nil {
+			t.Error("I2PAddrPortFrom should have failed for a TCP address")
+		}
+		if _, err := I2PAddrPortFrom((*I2PAddrPort)(nil)); err == nil {
+			t.Error("I2PAddrPortFrom should have failed for a nil *I2PAddrPort")
+		}
+	})
+}
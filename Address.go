@@ -0,0 +1,119 @@
+package i2pkeys
+
+import (
+	"crypto/sha256"
+	"fmt"
+)
+
+// Address is a parsed, validated destination. Unlike I2PAddr, whose methods
+// decode and hash the base64 string on every call, it computes its binary
+// form, hash and .b32.i2p address once, so its accessors do not allocate,
+// except Bytes, which returns a copy; AppendBytes reuses a buffer instead.
+// Address values are immutable and comparable with ==.
+type Address struct {
+	b64        I2PAddr
+	raw        string
+	hash       I2PDestHash
+	b32        string
+	sigType    SigType
+	cryptoType CryptoType
+}
+
+// ParseAddress validates a base64 destination and precomputes its forms.
+func ParseAddress(addr I2PAddr) (Address, error) {
+	b, err := addr.ToBytes()
+	if err != nil {
+		return Address{}, fmt.Errorf("error decoding address: %w", err)
+	}
+	return newAddress(addr, b)
+}
+
+// NewAddressFromBytes validates a binary destination and precomputes its
+// forms.
+func NewAddressFromBytes(b []byte) (Address, error) {
+	return newAddress(I2PAddr(i2pB64enc.EncodeToString(b)), b)
+}
+
+func newAddress(addr I2PAddr, b []byte) (Address, error) {
+	kc, err := readKeysAndCert(b)
+	if err != nil {
+		return Address{}, fmt.Errorf("invalid address: %w", err)
+	}
+	if len(kc.raw) != len(b) {
+		return Address{}, fmt.Errorf("invalid address: trailing data after destination: %d bytes", len(b)-len(kc.raw))
+	}
+	hash := I2PDestHash(sha256.Sum256(b))
+	return Address{
+		b64:        addr,
+		raw:        string(b),
+		hash:       hash,
+		b32:        hash.String(),
+		sigType:    kc.sigType,
+		cryptoType: kc.cryptoType,
+	}, nil
+}
+
+// ParsedAddress parses the keys' destination, see ParseAddress.
+func (k I2PKeys) ParsedAddress() (Address, error) {
+	return ParseAddress(k.Address)
+}
+
+// IsZero reports whether a is the zero Address.
+func (a Address) IsZero() bool {
+	return a.raw == ""
+}
+
+// I2PAddr returns the base64 destination.
+func (a Address) I2PAddr() I2PAddr {
+	return a.b64
+}
+
+// Base64 returns the base64 destination.
+func (a Address) Base64() string {
+	return string(a.b64)
+}
+
+// Base32 returns the .b32.i2p address.
+func (a Address) Base32() string {
+	return a.b32
+}
+
+// DestHash returns the destination hash.
+func (a Address) DestHash() I2PDestHash {
+	return a.hash
+}
+
+// Bytes returns a copy of the binary destination.
+func (a Address) Bytes() []byte {
+	return []byte(a.raw)
+}
+
+// AppendBytes appends the binary destination to dst.
+func (a Address) AppendBytes(dst []byte) []byte {
+	return append(dst, a.raw...)
+}
+
+// Len returns the length of the binary destination.
+func (a Address) Len() int {
+	return len(a.raw)
+}
+
+// SigType returns the signature type of the destination.
+func (a Address) SigType() SigType {
+	return a.sigType
+}
+
+// CryptoType returns the encryption type of the destination.
+func (a Address) CryptoType() CryptoType {
+	return a.cryptoType
+}
+
+// String returns the .b32.i2p address.
+func (a Address) String() string {
+	return a.b32
+}
+
+// Network returns "I2P", so that Address implements net.Addr.
+func (a Address) Network() string {
+	return "I2P"
+}
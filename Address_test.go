@@ -0,0 +1,134 @@
+package i2pkeys
+
+import (
+	"bytes"
+	"net"
+	"testing"
+)
+
+var _ net.Addr = Address{}
+
+func Test_Address(t *testing.T) {
+	i2paddr := I2PAddr(validI2PAddrB64)
+
+	t.Run("Matches I2PAddr", func(t *testing.T) {
+		a, err := ParseAddress(i2paddr)
+		if err != nil {
+			t.Fatalf("ParseAddress failed: %v", err)
+		}
+		if a.Base32() != i2paddr.Base32() || a.Base64() != i2paddr.Base64() || a.DestHash() != i2paddr.DestHash() {
+			t.Error("Address does not match I2PAddr")
+		}
+		if !bytes.Equal(a.Bytes(), i2paddr.Bytes()) || a.Len() != len(i2paddr.Bytes()) || a.I2PAddr() != i2paddr {
+			t.Error("Address bytes do not match I2PAddr")
+		}
+		if a.SigType() != SigTypeEd25519 || a.String() != a.Base32() || a.IsZero() {
+			t.Errorf("Unexpected Address %v", a)
+		}
+		b, err := NewAddressFromBytes(i2paddr.Bytes())
+		if err != nil {
+			t.Fatalf("NewAddressFromBytes failed: %v", err)
+		}
+		if a != b {
+			t.Error("Addresses from string and bytes differ")
+		}
+	})
+
+	t.Run("Immutable", func(t *testing.T) {
+		a, _ := ParseAddress(i2paddr)
+		b := a.Bytes()
+		b[0] ^= 0xff
+		if a.Bytes()[0] == b[0] {
+			t.Error("Modifying Bytes changed the Address")
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		if _, err := ParseAddress(i2paddr[:len(i2paddr)-4]); err == nil {
+			t.Error("ParseAddress should have failed for a truncated destination")
+		}
+		if _, err := ParseAddress("not base64!"); err == nil {
+			t.Error("ParseAddress should have failed for invalid base64")
+		}
+		if _, err := NewAddressFromBytes(append(i2paddr.Bytes(), 0)); err == nil {
+			t.Error("NewAddressFromBytes should have failed for trailing data")
+		}
+	})
+
+	t.Run("Allocation free", func(t *testing.T) {
+		a, _ := ParseAddress(i2paddr)
+		buf := make([]byte, 0, 1024)
+		allocs := testing.AllocsPerRun(100, func() {
+			_ = a.Base32()
+			_ = a.DestHash()
+			_ = a.Base64()
+			_ = a.AppendBytes(buf[:0])
+		})
+		if allocs != 0 {
+			t.Errorf("Accessors allocated %.0f times", allocs)
+		}
+	})
+}
+
+func BenchmarkI2PAddrBase32(b *testing.B) {
+	addr := I2PAddr(validI2PAddrB64)
+	for i := 0; i < b.N; i++ {
+		_ = addr.Base32()
+	}
+}
+
+func BenchmarkAddressBase32(b *testing.B) {
+	addr, _ := ParseAddress(I2PAddr(validI2PAddrB64))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = addr.Base32()
+	}
+}
+
+func BenchmarkI2PAddrDestHash(b *testing.B) {
+	addr := I2PAddr(validI2PAddrB64)
+	for i := 0; i < b.N; i++ {
+		_ = addr.DestHash()
+	}
+}
+
+func BenchmarkAddressDestHash(b *testing.B) {
+	addr, _ := ParseAddress(I2PAddr(validI2PAddrB64))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = addr.DestHash()
+	}
+}
+
+func BenchmarkI2PAddrBytes(b *testing.B) {
+	addr := I2PAddr(validI2PAddrB64)
+	for i := 0; i < b.N; i++ {
+		_ = addr.Bytes()
+	}
+}
+
+// benchBytes keeps the compiler from optimizing the copy of Bytes away.
+var benchBytes []byte
+
+func BenchmarkAddressBytes(b *testing.B) {
+	addr, _ := ParseAddress(I2PAddr(validI2PAddrB64))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchBytes = addr.Bytes()
+	}
+}
+
+func BenchmarkAddressAppendBytes(b *testing.B) {
+	addr, _ := ParseAddress(I2PAddr(validI2PAddrB64))
+	buf := make([]byte, 0, 1024)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf = addr.AppendBytes(buf[:0])
+	}
+}
+
+func BenchmarkParseAddress(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, _ = ParseAddress(I2PAddr(validI2PAddrB64))
+	}
+}
@@ -0,0 +1,188 @@
+package i2pkeys
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"strings"
+	"time"
+
+	"filippo.io/edwards25519"
+)
+
+const (
+	blindedFlagTwoByteSigTypes byte = 0x01
+	blindedFlagSecret          byte = 0x02
+	blindedFlagPerClientAuth   byte = 0x04
+)
+
+// BlindedAddress is the "b33" address of a service publishing an encrypted
+// LeaseSet2. Unlike a regular .b32.i2p address, which is the hash of the
+// destination, it holds the destination's unblinded signing public key, from
+// which clients compute the blinded key the LeaseSet is stored under for the
+// current day. Only Ed25519 and RedDSA destinations can be blinded.
+type BlindedAddress struct {
+	SigType        SigType // type of PublicKey, Ed25519 or RedDSA
+	BlindedSigType SigType // type of the blinded key, always RedDSA
+	PublicKey      []byte  // the unblinded signing public key
+	SecretRequired bool    // a secret is needed to compute the blinded key
+	PerClientAuth  bool    // clients need authorization keys to decrypt
+}
+
+// NewBlindedAddress returns the b33 address of a destination.
+func NewBlindedAddress(addr I2PAddr, secretRequired, perClientAuth bool) (BlindedAddress, error) {
+	kc, err := addr.keysAndCert()
+	if err != nil {
+		return BlindedAddress{}, err
+	}
+	if kc.sigType != SigTypeEd25519 && kc.sigType != SigTypeRedDSAEd25519 {
+		return BlindedAddress{}, fmt.Errorf("%w: %s destinations cannot be blinded", ErrUnsupportedSigType, kc.sigType)
+	}
+	return BlindedAddress{
+		SigType:        kc.sigType,
+		BlindedSigType: SigTypeRedDSAEd25519,
+		PublicKey:      append([]byte{}, kc.signingKey...),
+		SecretRequired: secretRequired,
+		PerClientAuth:  perClientAuth,
+	}, nil
+}
+
+// NewBlindedAddressFromString parses a b33 address, with or without the
+// .b32.i2p suffix, and verifies its checksum.
+func NewBlindedAddressFromString(str string) (BlindedAddress, error) {
+	log.WithField("address", str).Debug("Parsing blinded address")
+	str = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(str)), ".b32.i2p")
+	if len(str) < 56 {
+		return BlindedAddress{}, errors.New("too short for a blinded address")
+	}
+	if pad := len(str) % 8; pad != 0 {
+		str += strings.Repeat("=", 8-pad)
+	}
+	data, err := i2pB32enc.DecodeString(str)
+	if err != nil {
+		return BlindedAddress{}, fmt.Errorf("error decoding blinded address: %w", err)
+	}
+	crc := crc32.ChecksumIEEE(data[3:])
+	data[0] ^= byte(crc)
+	data[1] ^= byte(crc >> 8)
+	data[2] ^= byte(crc >> 16)
+
+	var b BlindedAddress
+	flags := data[0]
+	if flags&^(blindedFlagTwoByteSigTypes|blindedFlagSecret|blindedFlagPerClientAuth) != 0 {
+		return BlindedAddress{}, errors.New("invalid blinded address: bad checksum or unknown flags")
+	}
+	b.SecretRequired = flags&blindedFlagSecret != 0
+	b.PerClientAuth = flags&blindedFlagPerClientAuth != 0
+	rest := data[1:]
+	if flags&blindedFlagTwoByteSigTypes != 0 {
+		if len(rest) < 4 {
+			return BlindedAddress{}, errors.New("blinded address truncated")
+		}
+		b.SigType = SigType(binary.BigEndian.Uint16(rest[0:2]))
+		b.BlindedSigType = SigType(binary.BigEndian.Uint16(rest[2:4]))
+		rest = rest[4:]
+	} else {
+		b.SigType = SigType(rest[0])
+		b.BlindedSigType = SigType(rest[1])
+		rest = rest[2:]
+	}
+	if b.SigType != SigTypeEd25519 && b.SigType != SigTypeRedDSAEd25519 {
+		return BlindedAddress{}, fmt.Errorf("invalid blinded address: bad checksum or %w %s", ErrUnsupportedSigType, b.SigType)
+	}
+	if b.BlindedSigType != SigTypeRedDSAEd25519 {
+		return BlindedAddress{}, fmt.Errorf("invalid blinded address: bad checksum or %w %s", ErrUnsupportedSigType, b.BlindedSigType)
+	}
+	if len(rest) != b.SigType.PublicKeyLen() {
+		return BlindedAddress{}, fmt.Errorf("invalid blinded address: public key length %d", len(rest))
+	}
+	b.PublicKey = rest
+	return b, nil
+}
+
+// String returns the b33 address, ending in .b32.i2p
+func (b BlindedAddress) String() string {
+	var flags byte
+	if b.SecretRequired {
+		flags |= blindedFlagSecret
+	}
+	if b.PerClientAuth {
+		flags |= blindedFlagPerClientAuth
+	}
+	data := []byte{flags}
+	if b.SigType > 0xff || b.BlindedSigType > 0xff {
+		data[0] |= blindedFlagTwoByteSigTypes
+		data = binary.BigEndian.AppendUint16(data, uint16(b.SigType))
+		data = binary.BigEndian.AppendUint16(data, uint16(b.BlindedSigType))
+	} else {
+		data = append(data, byte(b.SigType), byte(b.BlindedSigType))
+	}
+	data = append(data, b.PublicKey...)
+	crc := crc32.ChecksumIEEE(data[3:])
+	data[0] ^= byte(crc)
+	data[1] ^= byte(crc >> 8)
+	data[2] ^= byte(crc >> 16)
+	return strings.TrimRight(i2pB32enc.EncodeToString(data), "=") + ".b32.i2p"
+}
+
+// blindingAlpha computes the blinding factor for the UTC day of date:
+//
+//	keydata = A || stA || stA'
+//	seed = HKDF(SHA-256("I2PGenerateAlpha" || keydata), "yyyyMMdd" || secret, "i2pblinding1", 64)
+//	alpha = seed mod L
+func (b BlindedAddress) blindingAlpha(date time.Time, secret string) (*edwards25519.Scalar, error) {
+	if len(b.PublicKey) != 32 {
+		return nil, fmt.Errorf("invalid public key length %d", len(b.PublicKey))
+	}
+	if b.SecretRequired && secret == "" {
+		return nil, errors.New("blinded address requires a secret")
+	}
+	if !b.SecretRequired && secret != "" {
+		return nil, errors.New("blinded address does not use a secret")
+	}
+	keydata := append([]byte{}, b.PublicKey...)
+	keydata = binary.BigEndian.AppendUint16(keydata, uint16(b.SigType))
+	keydata = binary.BigEndian.AppendUint16(keydata, uint16(b.BlindedSigType))
+	salt := sha256.Sum256(append([]byte("I2PGenerateAlpha"), keydata...))
+	ikm := append([]byte(date.UTC().Format("20060102")), secret...)
+	seed, err := hkdfSHA256(ikm, salt[:], "i2pblinding1", 64)
+	if err != nil {
+		return nil, err
+	}
+	return edwards25519.NewScalar().SetUniformBytes(seed)
+}
+
+// BlindedPublicKey computes the blinded public key A' = A + alpha*B for the
+// UTC day of date. secret must be set if SecretRequired is set, and empty
+// otherwise.
+func (b BlindedAddress) BlindedPublicKey(date time.Time, secret string) ([]byte, error) {
+	alpha, err := b.blindingAlpha(date, secret)
+	if err != nil {
+		return nil, err
+	}
+	a, err := new(edwards25519.Point).SetBytes(b.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	blinded := new(edwards25519.Point).Add(a, new(edwards25519.Point).ScalarBaseMult(alpha))
+	return blinded.Bytes(), nil
+}
+
+// BlindedHash returns the hash the encrypted LeaseSet is stored under in the
+// network database for the UTC day of date, SHA-256(stA' || A').
+func (b BlindedAddress) BlindedHash(date time.Time, secret string) (I2PDestHash, error) {
+	key, err := b.BlindedPublicKey(date, secret)
+	if err != nil {
+		return I2PDestHash{}, err
+	}
+	data := binary.BigEndian.AppendUint16(nil, uint16(b.BlindedSigType))
+	return I2PDestHash(sha256.Sum256(append(data, key...))), nil
+}
+
+// IsBlindedAddress reports whether str looks like a b33 address rather than a
+// regular 52 character .b32.i2p address. It does not verify the checksum.
+func IsBlindedAddress(str string) bool {
+	return strings.HasSuffix(str, ".b32.i2p") && len(str) >= 56+len(".b32.i2p")
+}
@@ -0,0 +1,166 @@
+package i2pkeys
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"filippo.io/edwards25519"
+)
+
+func Test_BlindedAddress(t *testing.T) {
+	keys, err := NewKeysFromSeed(bytes.Repeat([]byte{0x33}, SeedSize))
+	if err != nil {
+		t.Fatalf("NewKeysFromSeed failed: %v", err)
+	}
+	b33, err := NewBlindedAddress(keys.Addr(), false, false)
+	if err != nil {
+		t.Fatalf("NewBlindedAddress failed: %v", err)
+	}
+	day := time.Date(2026, 10, 19, 13, 0, 0, 0, time.UTC)
+
+	t.Run("Encoding", func(t *testing.T) {
+		str := b33.String()
+		if len(str) != 56+len(".b32.i2p") || !strings.HasSuffix(str, ".b32.i2p") {
+			t.Errorf("Unexpected b33 form %s", str)
+		}
+		if !IsBlindedAddress(str) || IsBlindedAddress(keys.Addr().Base32()) {
+			t.Error("IsBlindedAddress misclassified an address")
+		}
+		if _, err := DestHashFromString(str); err == nil {
+			t.Error("DestHashFromString should have failed for b33 address")
+		}
+		for _, flags := range [][2]bool{{false, false}, {true, false}, {false, true}, {true, true}} {
+			b, _ := NewBlindedAddress(keys.Addr(), flags[0], flags[1])
+			parsed, err := NewBlindedAddressFromString(strings.ToUpper(b.String()))
+			if err != nil {
+				t.Fatalf("NewBlindedAddressFromString failed: %v", err)
+			}
+			if parsed.SecretRequired != flags[0] || parsed.PerClientAuth != flags[1] {
+				t.Errorf("Flags did not round trip: %+v", parsed)
+			}
+			if parsed.SigType != SigTypeEd25519 || parsed.BlindedSigType != SigTypeRedDSAEd25519 || !bytes.Equal(parsed.PublicKey, b.PublicKey) {
+				t.Errorf("Address did not round trip: %+v", parsed)
+			}
+		}
+	})
+
+	t.Run("Known answers", func(t *testing.T) {
+		// The public key of RFC 8032 test 1. The expected values were
+		// computed with a separate implementation of the b33 format and
+		// GENERATE_ALPHA as given in the encrypted LeaseSet specification.
+		pub, _ := hex.DecodeString("d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a")
+		b := BlindedAddress{SigType: SigTypeEd25519, BlindedSigType: SigTypeRedDSAEd25519, PublicKey: pub}
+		if got := b.String(); got != "wia2tv22taayfmikw7kux7wtzfsaooqo4fzphwvgems26aq2nd3qoui2.b32.i2p" {
+			t.Errorf("String() = %s", got)
+		}
+		date := time.Date(2024, 1, 1, 23, 59, 0, 0, time.UTC)
+		key, err := b.BlindedPublicKey(date, "")
+		if err != nil || hex.EncodeToString(key) != "f5acc82dee97d7fd98fc2b03ec612ced100aa33a51d14c59e1d87971e0776254" {
+			t.Errorf("BlindedPublicKey() = %x, %v", key, err)
+		}
+		hash, err := b.BlindedHash(date, "")
+		if err != nil || hash.String() != "qp2nfmqnlgj4oc6o3gsp5e5rz3yp63az45g6wtr4owajqjav6zaq.b32.i2p" {
+			t.Errorf("BlindedHash() = %s, %v", hash, err)
+		}
+
+		b.SecretRequired, b.PerClientAuth = true, true
+		if got := b.String(); got != "wqa2tv22taayfmikw7kux7wtzfsaooqo4fzphwvgems26aq2nd3qoui2.b32.i2p" {
+			t.Errorf("String() with flags = %s", got)
+		}
+		key, err = b.BlindedPublicKey(date, "hunter2")
+		if err != nil || hex.EncodeToString(key) != "1da08a192928ff6fe70712a0ba65ae08ef4beb2a332a157d51936ef35a03c00f" {
+			t.Errorf("BlindedPublicKey() with secret = %x, %v", key, err)
+		}
+	})
+
+	t.Run("Checksum", func(t *testing.T) {
+		str := []byte(b33.String())
+		for _, i := range []int{0, 3, 20, 55} {
+			typo := append([]byte{}, str...)
+			if typo[i] == 'a' {
+				typo[i] = 'b'
+			} else {
+				typo[i] = 'a'
+			}
+			if _, err := NewBlindedAddressFromString(string(typo)); err == nil {
+				t.Errorf("NewBlindedAddressFromString should have failed for typo at %d", i)
+			}
+		}
+	})
+
+	t.Run("Blinded key", func(t *testing.T) {
+		blinded, err := b33.BlindedPublicKey(day, "")
+		if err != nil {
+			t.Fatalf("BlindedPublicKey failed: %v", err)
+		}
+		sameDay, _ := b33.BlindedPublicKey(day.Add(10*time.Hour), "")
+		if !bytes.Equal(blinded, sameDay) {
+			t.Error("Blinded key changed within a UTC day")
+		}
+		nextDay, _ := b33.BlindedPublicKey(day.Add(24*time.Hour), "")
+		secretB33 := b33
+		secretB33.SecretRequired = true
+		withSecret, _ := secretB33.BlindedPublicKey(day, "secret")
+		otherSecret, _ := secretB33.BlindedPublicKey(day, "other")
+		if bytes.Equal(blinded, nextDay) || bytes.Equal(withSecret, otherSecret) || bytes.Equal(blinded, b33.PublicKey) {
+			t.Error("Blinded key does not depend on date and secret")
+		}
+
+		p, _ := keys.privateKeyFile()
+		priv, err := testBlindedPrivateKey(b33, p.signingPrivateKey, day, "")
+		if err != nil {
+			t.Fatalf("blindedPrivateKey failed: %v", err)
+		}
+		if !bytes.Equal(new(edwards25519.Point).ScalarBaseMult(priv).Bytes(), blinded) {
+			t.Error("Blinded private key does not match blinded public key")
+		}
+
+		hash, err := b33.BlindedHash(day, "")
+		if err != nil {
+			t.Fatalf("BlindedHash failed: %v", err)
+		}
+		if hash == keys.Addr().DestHash() {
+			t.Error("Blinded hash should differ from the destination hash")
+		}
+	})
+
+	t.Run("Secret mismatch", func(t *testing.T) {
+		if _, err := b33.BlindedPublicKey(day, "secret"); err == nil {
+			t.Error("BlindedPublicKey should have failed with a secret the address does not use")
+		}
+		secretB33 := b33
+		secretB33.SecretRequired = true
+		if _, err := secretB33.BlindedPublicKey(day, ""); err == nil {
+			t.Error("BlindedPublicKey should have failed without the required secret")
+		}
+		if _, err := secretB33.BlindedHash(day, ""); err == nil {
+			t.Error("BlindedHash should have failed without the required secret")
+		}
+	})
+
+	t.Run("Unsupported destination", func(t *testing.T) {
+		p := testPrivateKeyFile(t, CryptoTypeElGamal, SigTypeECDSASHA256P256)
+		if _, err := NewBlindedAddress(p.keys().Addr(), false, false); err == nil {
+			t.Error("NewBlindedAddress should have failed for ECDSA destination")
+		}
+	})
+}
+
+// testBlindedPrivateKey returns the blinded private scalar a' = a + alpha for
+// an Ed25519 signing private key (a seed), matching BlindedPublicKey.
+func testBlindedPrivateKey(b BlindedAddress, seed []byte, date time.Time, secret string) (*edwards25519.Scalar, error) {
+	alpha, err := b.blindingAlpha(date, secret)
+	if err != nil {
+		return nil, err
+	}
+	h := sha512.Sum512(seed)
+	a, err := edwards25519.NewScalar().SetBytesWithClamping(h[:32])
+	if err != nil {
+		return nil, err
+	}
+	return a.Add(a, alpha), nil
+}
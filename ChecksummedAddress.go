@@ -0,0 +1,273 @@
+package i2pkeys
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The checksummed encoding of I2PDestHash is bech32m (BIP 350) with the
+// human readable part "i2p", written in groups of five characters:
+//
+//	i2p1qqqqq-qqqqq-...-qqq
+//
+// The checksum detects any error in up to four characters, and single
+// character typos can be located and corrected.
+const (
+	checksumHRP       = "i2p"
+	checksumAlphabet  = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	checksumConst     = 0x2bc830a3
+	checksumLen       = 6
+	checksumDataChars = 52 // 256 bits in 5 bit groups
+	checksumGroup     = 5
+)
+
+var checksumRev = func() (rev [256]int8) {
+	for i := range rev {
+		rev[i] = -1
+	}
+	for i, c := range checksumAlphabet {
+		rev[c] = int8(i)
+	}
+	return rev
+}()
+
+// TypoError is returned when an address does not parse or, for checksummed
+// addresses, fails its checksum. It points out the likely typos.
+type TypoError struct {
+	Message    string
+	Positions  []int  // byte offsets of suspicious characters in the input
+	Suggestion string // the corrected address if one was found
+}
+
+func (e *TypoError) Error() string {
+	if e.Suggestion != "" {
+		return fmt.Sprintf("%s, did you mean %s?", e.Message, e.Suggestion)
+	}
+	return e.Message
+}
+
+func checksumPolymod(values []byte) uint32 {
+	gen := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+func checksumHRPExpand() []byte {
+	v := make([]byte, 0, 2*len(checksumHRP)+1)
+	for _, c := range checksumHRP {
+		v = append(v, byte(c>>5))
+	}
+	v = append(v, 0)
+	for _, c := range checksumHRP {
+		v = append(v, byte(c&31))
+	}
+	return v
+}
+
+func checksumValid(data []byte) bool {
+	return checksumPolymod(append(checksumHRPExpand(), data...)) == checksumConst
+}
+
+// Checksummed returns the hash in the checksummed encoding, for showing to
+// people who will read or type it.
+func (h I2PDestHash) Checksummed() string {
+	data := make([]byte, 0, checksumDataChars+checksumLen)
+	var acc, bits uint
+	for _, b := range h {
+		acc = acc<<8 | uint(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			data = append(data, byte(acc>>bits&31))
+		}
+	}
+	data = append(data, byte(acc<<(5-bits)&31))
+	mod := checksumPolymod(append(append(checksumHRPExpand(), data...), make([]byte, checksumLen)...)) ^ checksumConst
+	for i := 0; i < checksumLen; i++ {
+		data = append(data, byte(mod>>(5*(5-i))&31))
+	}
+	var sb strings.Builder
+	sb.WriteString(checksumHRP + "1")
+	for i, d := range data {
+		if i > 0 && i%checksumGroup == 0 {
+			sb.WriteByte('-')
+		}
+		sb.WriteByte(checksumAlphabet[d])
+	}
+	return sb.String()
+}
+
+// ParseChecksummedHash parses an address written by Checksummed. Case,
+// spaces and dashes are ignored. A *TypoError is returned when the checksum
+// does not match; when a single typo explains the mismatch it names the
+// position and the corrected address.
+func ParseChecksummedHash(s string) (I2PDestHash, error) {
+	log.WithField("address", s).Debug("Parsing checksummed address")
+	var (
+		data      []byte
+		positions []int // input offset of each data character
+	)
+	lower := strings.ToLower(s)
+	if !strings.HasPrefix(strings.TrimSpace(lower), checksumHRP+"1") {
+		return I2PDestHash{}, &TypoError{Message: "checksummed address must start with " + checksumHRP + "1"}
+	}
+	start := strings.Index(lower, checksumHRP+"1") + len(checksumHRP) + 1
+	var bad []int
+	for i := start; i < len(lower); i++ {
+		c := lower[i]
+		if c == '-' || c == ' ' || c == '\t' {
+			continue
+		}
+		if checksumRev[c] < 0 {
+			bad = append(bad, i)
+			data = append(data, 0)
+		} else {
+			data = append(data, byte(checksumRev[c]))
+		}
+		positions = append(positions, i)
+	}
+	if len(data) != checksumDataChars+checksumLen {
+		return I2PDestHash{}, &TypoError{Message: fmt.Sprintf("checksummed address has %d characters, want %d", len(data), checksumDataChars+checksumLen)}
+	}
+	if len(bad) > 0 || !checksumValid(data) {
+		return I2PDestHash{}, checksumTypo(data, positions, bad)
+	}
+	h, ok := checksumDataToHash(data)
+	if !ok {
+		return I2PDestHash{}, errors.New("checksummed address has non-zero padding")
+	}
+	return h, nil
+}
+
+// checksumDataToHash converts the 5 bit groups back to the hash, and reports
+// whether the padding bits are zero.
+func checksumDataToHash(data []byte) (h I2PDestHash, ok bool) {
+	var acc, bits uint
+	n := 0
+	for _, d := range data[:checksumDataChars] {
+		acc = acc<<5 | uint(d)
+		bits += 5
+		if bits >= 8 {
+			bits -= 8
+			h[n] = byte(acc >> bits)
+			n++
+		}
+	}
+	return h, acc&(1<<bits-1) == 0
+}
+
+// checksumTypo looks for a single character substitution which makes the
+// checksum valid. bad holds the positions of characters outside the
+// alphabet, only those are tried if there are any.
+func checksumTypo(data []byte, positions, bad []int) error {
+	candidates := bad
+	if len(bad) > 1 {
+		return &TypoError{Message: "checksummed address contains invalid characters", Positions: bad}
+	}
+	if len(bad) == 0 {
+		candidates = positions
+	}
+	type fix struct{ index, value int }
+	var fixes []fix
+	for _, pos := range candidates {
+		idx := indexOf(positions, pos)
+		orig := data[idx]
+		for v := 0; v < 32; v++ {
+			if len(bad) == 0 && byte(v) == orig {
+				continue
+			}
+			data[idx] = byte(v)
+			if _, ok := checksumDataToHash(data); ok && checksumValid(data) {
+				fixes = append(fixes, fix{idx, v})
+			}
+		}
+		data[idx] = orig
+	}
+	if len(fixes) != 1 {
+		if len(bad) > 0 {
+			return &TypoError{Message: "checksummed address contains an invalid character", Positions: bad}
+		}
+		return &TypoError{Message: "checksummed address has more than one typo"}
+	}
+	data[fixes[0].index] = byte(fixes[0].value)
+	h, _ := checksumDataToHash(data)
+	return &TypoError{
+		Message:    fmt.Sprintf("checksummed address has a typo at position %d", positions[fixes[0].index]),
+		Positions:  []int{positions[fixes[0].index]},
+		Suggestion: h.Checksummed(),
+	}
+}
+
+func indexOf(s []int, v int) int {
+	for i, x := range s {
+		if x == v {
+			return i
+		}
+	}
+	return -1
+}
+
+// b32Confusables maps characters which are not in the base32 alphabet to
+// the letters people most likely meant.
+var b32Confusables = map[byte]byte{'0': 'o', '1': 'l', '8': 'b', '9': 'g'}
+
+// DiagnoseB32 checks a .b32.i2p address entered by a user, for instance one
+// which failed to resolve, for likely typos: characters outside the base32
+// alphabet, a wrong length or a mistyped suffix. It returns nil if the
+// address is well formed, which, as the format has no checksum, does not
+// mean it is the intended one; use the checksummed encoding for that.
+func DiagnoseB32(s string) error {
+	s = strings.ToLower(strings.TrimSpace(s))
+	name, suffix := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		name, suffix = s[:i], s[i:]
+	}
+	var (
+		positions []int
+		fixed     = []byte(name)
+		fixable   = true
+	)
+	for i := 0; i < len(name); i++ {
+		if strings.IndexByte("abcdefghijklmnopqrstuvwxyz234567", name[i]) >= 0 {
+			continue
+		}
+		positions = append(positions, i)
+		if c, ok := b32Confusables[name[i]]; ok {
+			fixed[i] = c
+		} else {
+			fixable = false
+		}
+	}
+	if len(positions) > 0 {
+		e := &TypoError{Message: "address contains characters which are not used in .b32.i2p addresses", Positions: positions}
+		if fixable && len(name) == 52 {
+			e.Suggestion = string(fixed) + ".b32.i2p"
+		}
+		return e
+	}
+	if len(name) >= 56 && suffix == ".b32.i2p" {
+		// A blinded address, its checksum is verified when it is parsed.
+		return nil
+	}
+	if len(name) != 52 {
+		return &TypoError{Message: fmt.Sprintf("address has %d characters before the suffix, want 52", len(name))}
+	}
+	// The last character only carries 1 bit of the hash, the rest is zero.
+	if strings.IndexByte("aq", name[51]) < 0 {
+		return &TypoError{Message: "last character of a .b32.i2p address must be a or q", Positions: []int{51}}
+	}
+	if suffix != ".b32.i2p" {
+		return &TypoError{Message: fmt.Sprintf("address ends in %q instead of .b32.i2p", suffix), Suggestion: name + ".b32.i2p"}
+	}
+	return nil
+}
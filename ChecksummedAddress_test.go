@@ -0,0 +1,99 @@
+package i2pkeys
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_ChecksummedAddress(t *testing.T) {
+	var vector I2PDestHash
+	for i := range vector {
+		vector[i] = byte(i * 7)
+	}
+	const vectorChecksummed = "i2p1qqrsu-9guyv-4rzwp-lgex4-gkmzd-9c8wl-593jf-e4gdg-47mtm-3xt6t-vsmyy-8rz"
+
+	t.Run("Known vector", func(t *testing.T) {
+		if got := vector.Checksummed(); got != vectorChecksummed {
+			t.Errorf("Checksummed() = %s, want %s", got, vectorChecksummed)
+		}
+	})
+
+	t.Run("Round trip", func(t *testing.T) {
+		h := I2PAddr(validI2PAddrB64).DestHash()
+		for _, s := range []string{h.Checksummed(), strings.ToUpper(h.Checksummed()), strings.ReplaceAll(h.Checksummed(), "-", " ")} {
+			got, err := ParseChecksummedHash(s)
+			if err != nil {
+				t.Fatalf("ParseChecksummedHash(%q) failed: %v", s, err)
+			}
+			if got != h {
+				t.Errorf("ParseChecksummedHash(%q) = %s, want %s", s, got, h)
+			}
+		}
+	})
+
+	t.Run("Single typo is corrected", func(t *testing.T) {
+		typo := []byte(vectorChecksummed)
+		typo[20] = 'q'
+		_, err := ParseChecksummedHash(string(typo))
+		var te *TypoError
+		if !errors.As(err, &te) {
+			t.Fatalf("Expected a TypoError, got %v", err)
+		}
+		if te.Suggestion != vectorChecksummed || len(te.Positions) != 1 || te.Positions[0] != 20 {
+			t.Errorf("Unexpected TypoError %+v", te)
+		}
+	})
+
+	t.Run("Invalid character is corrected", func(t *testing.T) {
+		typo := strings.Replace(vectorChecksummed, "wp", "wb", 1)
+		_, err := ParseChecksummedHash(typo)
+		var te *TypoError
+		if !errors.As(err, &te) || te.Suggestion != vectorChecksummed {
+			t.Errorf("Expected a correction, got %v", err)
+		}
+	})
+
+	t.Run("Transposition is detected", func(t *testing.T) {
+		typo := []byte(vectorChecksummed)
+		typo[6], typo[7] = typo[7], typo[6]
+		if _, err := ParseChecksummedHash(string(typo)); err == nil {
+			t.Error("ParseChecksummedHash should have failed for swapped characters")
+		}
+	})
+
+	t.Run("Wrong length", func(t *testing.T) {
+		if _, err := ParseChecksummedHash(vectorChecksummed[:len(vectorChecksummed)-1]); err == nil {
+			t.Error("ParseChecksummedHash should have failed for a truncated address")
+		}
+		if _, err := ParseChecksummedHash(vector.String()); err == nil {
+			t.Error("ParseChecksummedHash should have failed for a .b32.i2p address")
+		}
+	})
+}
+
+func Test_DiagnoseB32(t *testing.T) {
+	valid := I2PAddr(validI2PAddrB64).Base32()
+	if err := DiagnoseB32(valid); err != nil {
+		t.Errorf("DiagnoseB32(%s) = %v", valid, err)
+	}
+	b33, _ := NewBlindedAddress(I2PAddr(validI2PAddrB64), false, false)
+	if err := DiagnoseB32(b33.String()); err != nil {
+		t.Errorf("DiagnoseB32(%s) = %v", b33, err)
+	}
+
+	typo := "0" + valid[1:]
+	var te *TypoError
+	if err := DiagnoseB32(typo); !errors.As(err, &te) || te.Suggestion != "o"+valid[1:] || te.Positions[0] != 0 {
+		t.Errorf("DiagnoseB32(%s) = %v", typo, err)
+	}
+	if err := DiagnoseB32(valid[:51] + ".b32.i2p"); err == nil {
+		t.Error("DiagnoseB32 should have flagged a short address")
+	}
+	if err := DiagnoseB32(strings.Replace(valid, ".b32.i2p", ".b32.12p", 1)); !errors.As(err, &te) || te.Suggestion != valid {
+		t.Errorf("DiagnoseB32 should have corrected the suffix, got %v", err)
+	}
+	if err := DiagnoseB32(valid[:51] + "z.b32.i2p"); err == nil {
+		t.Error("DiagnoseB32 should have flagged an impossible last character")
+	}
+}
@@ -0,0 +1,280 @@
+package i2pkeys
+
+import (
+	"bufio"
+	"crypto/ecdh"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ClientAuthType is the per-client authorization scheme of an encrypted
+// LeaseSet. The values match the i2cp.leaseSetAuthType option.
+type ClientAuthType int
+
+const (
+	ClientAuthNone ClientAuthType = 0
+	ClientAuthDH   ClientAuthType = 1 // X25519 key per client
+	ClientAuthPSK  ClientAuthType = 2 // pre-shared key per client
+)
+
+func (t ClientAuthType) String() string {
+	switch t {
+	case ClientAuthNone:
+		return "none"
+	case ClientAuthDH:
+		return "dh"
+	case ClientAuthPSK:
+		return "psk"
+	}
+	return "ClientAuthType(" + strconv.Itoa(int(t)) + ")"
+}
+
+func parseClientAuthType(s string) (ClientAuthType, error) {
+	for _, t := range []ClientAuthType{ClientAuthNone, ClientAuthDH, ClientAuthPSK} {
+		if s == t.String() {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown client authorization type %q", s)
+}
+
+// ClientAuth is the credential of one client of a service with per-client
+// authorization. The service only keeps ServerKey, the client needs
+// ClientKey, which for DH is the client's X25519 private key and for PSK is
+// the same as ServerKey.
+type ClientAuth struct {
+	Name      string
+	Type      ClientAuthType
+	ServerKey []byte // DH: client's X25519 public key. PSK: the pre-shared key
+	ClientKey []byte // the client's secret, nil when loaded from the service's list
+}
+
+// ClientOptions returns the tunnel option clients configure to decrypt the
+// service's LeaseSet, accepted by i2pd client tunnels and as an I2CP option
+// by Java I2P.
+func (c ClientAuth) ClientOptions() (map[string]string, error) {
+	if len(c.ClientKey) != 32 {
+		return nil, errors.New("client key not available")
+	}
+	return map[string]string{"i2cp.leaseSetPrivKey": i2pB64enc.EncodeToString(c.ClientKey)}, nil
+}
+
+// ClientAuthList manages the authorized clients of one service. It holds no
+// client secrets for DH clients, only their public keys, and may be stored
+// with StoreClientAuthList.
+type ClientAuthList struct {
+	Service I2PAddr
+	Type    ClientAuthType
+	clients map[string]ClientAuth
+}
+
+// NewClientAuthList creates an empty list of authorized clients for the
+// service identified by keys.
+func NewClientAuthList(keys I2PKeys, authType ClientAuthType) (*ClientAuthList, error) {
+	if authType != ClientAuthDH && authType != ClientAuthPSK {
+		return nil, fmt.Errorf("invalid client authorization type %s", authType)
+	}
+	if _, err := keys.privateKeyFile(); err != nil {
+		return nil, err
+	}
+	return &ClientAuthList{Service: keys.Address, Type: authType, clients: make(map[string]ClientAuth)}, nil
+}
+
+func validClientName(name string) error {
+	if name == "" || strings.ContainsAny(name, ":=#\r\n") || strings.TrimSpace(name) != name {
+		return fmt.Errorf("invalid client name %q", name)
+	}
+	return nil
+}
+
+// Add creates credentials for a new client. The returned ClientAuth holds
+// the client's secret, which must be handed to the client and is not kept in
+// the list.
+func (l *ClientAuthList) Add(name string) (ClientAuth, error) {
+	log.WithField("client", name).Debug("Adding authorized client")
+	if err := validClientName(name); err != nil {
+		return ClientAuth{}, err
+	}
+	if _, ok := l.clients[name]; ok {
+		return ClientAuth{}, fmt.Errorf("client %q already exists", name)
+	}
+	c := ClientAuth{Name: name, Type: l.Type}
+	switch l.Type {
+	case ClientAuthDH:
+		priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+		if err != nil {
+			return ClientAuth{}, fmt.Errorf("error generating client key: %w", err)
+		}
+		c.ClientKey = priv.Bytes()
+		c.ServerKey = priv.PublicKey().Bytes()
+	case ClientAuthPSK:
+		c.ClientKey = make([]byte, 32)
+		if _, err := rand.Read(c.ClientKey); err != nil {
+			return ClientAuth{}, fmt.Errorf("error generating client key: %w", err)
+		}
+		c.ServerKey = append([]byte{}, c.ClientKey...)
+	}
+	l.clients[name] = ClientAuth{Name: name, Type: l.Type, ServerKey: c.ServerKey}
+	return c, nil
+}
+
+// AddPublicKey authorizes a DH client which generated its own X25519 key
+// pair, so that its private key never leaves the client.
+func (l *ClientAuthList) AddPublicKey(name string, pub []byte) error {
+	if l.Type != ClientAuthDH {
+		return errors.New("public keys can only be added for DH authorization")
+	}
+	if err := validClientName(name); err != nil {
+		return err
+	}
+	if _, ok := l.clients[name]; ok {
+		return fmt.Errorf("client %q already exists", name)
+	}
+	if _, err := ecdh.X25519().NewPublicKey(pub); err != nil {
+		return fmt.Errorf("invalid client public key: %w", err)
+	}
+	l.clients[name] = ClientAuth{Name: name, Type: l.Type, ServerKey: append([]byte{}, pub...)}
+	return nil
+}
+
+// Revoke removes a client. The service must publish a new LeaseSet for the
+// revocation to take effect.
+func (l *ClientAuthList) Revoke(name string) error {
+	log.WithField("client", name).Debug("Revoking authorized client")
+	if _, ok := l.clients[name]; !ok {
+		return fmt.Errorf("no client %q", name)
+	}
+	delete(l.clients, name)
+	return nil
+}
+
+// Clients returns the authorized clients sorted by name.
+func (l *ClientAuthList) Clients() []ClientAuth {
+	clients := make([]ClientAuth, 0, len(l.clients))
+	for _, c := range l.clients {
+		clients = append(clients, c)
+	}
+	sort.Slice(clients, func(i, j int) bool { return clients[i].Name < clients[j].Name })
+	return clients
+}
+
+// BlindedAddress returns the b33 address clients use to reach the service.
+func (l *ClientAuthList) BlindedAddress() (BlindedAddress, error) {
+	return NewBlindedAddress(l.Service, false, true)
+}
+
+// ServerOptions returns the I2CP options which publish an encrypted
+// LeaseSet2 readable by the listed clients, in the form both Java I2P and
+// i2pd server tunnels accept.
+func (l *ClientAuthList) ServerOptions() map[string]string {
+	opts := map[string]string{
+		"i2cp.leaseSetType":     "5",
+		"i2cp.leaseSetAuthType": strconv.Itoa(int(l.Type)),
+	}
+	for i, c := range l.Clients() {
+		opts["i2cp.leaseSetClient."+l.Type.String()+"."+strconv.Itoa(i)] = c.Name + ":" + i2pB64enc.EncodeToString(c.ServerKey)
+	}
+	return opts
+}
+
+func writeOptions(w io.Writer, opts map[string]string, prefix, sep string) error {
+	keys := make([]string, 0, len(opts))
+	for k := range opts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if _, err := fmt.Fprintf(w, "%s%s%s%s\n", prefix, k, sep, opts[k]); err != nil {
+			return fmt.Errorf("error writing options: %w", err)
+		}
+	}
+	return nil
+}
+
+// WriteJavaConfig writes the server options as lines for tunnel number
+// tunnel of Java I2P's i2ptunnel.config.
+func (l *ClientAuthList) WriteJavaConfig(w io.Writer, tunnel int) error {
+	return writeOptions(w, l.ServerOptions(), "tunnel."+strconv.Itoa(tunnel)+".option.", "=")
+}
+
+// WriteI2PDConfig writes the server options as lines for a tunnel section of
+// i2pd's tunnels.conf.
+func (l *ClientAuthList) WriteI2PDConfig(w io.Writer) error {
+	return writeOptions(w, l.ServerOptions(), "", " = ")
+}
+
+// StoreClientAuthList writes the list in a simple properties format, which
+// holds no client secrets for DH clients.
+func StoreClientAuthList(l *ClientAuthList, w io.Writer) error {
+	opts := map[string]string{
+		"service": l.Service.Base64(),
+		"type":    l.Type.String(),
+	}
+	for _, c := range l.Clients() {
+		opts["client."+c.Name] = i2pB64enc.EncodeToString(c.ServerKey)
+	}
+	if _, err := fmt.Fprintf(w, "# client authorization for %s\n", l.Service.Base32()); err != nil {
+		return fmt.Errorf("error writing options: %w", err)
+	}
+	return writeOptions(w, opts, "", "=")
+}
+
+// LoadClientAuthList reads a list written by StoreClientAuthList. Invalid
+// or duplicate client names are rejected.
+func LoadClientAuthList(r io.Reader) (*ClientAuthList, error) {
+	l := &ClientAuthList{clients: make(map[string]ClientAuth)}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		k, v, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: missing =", line)
+		}
+		var err error
+		switch {
+		case k == "service":
+			l.Service, err = NewI2PAddrFromString(v)
+		case k == "type":
+			l.Type, err = parseClientAuthType(v)
+		case strings.HasPrefix(k, "client."):
+			name := strings.TrimPrefix(k, "client.")
+			if err = validClientName(name); err != nil {
+				break
+			}
+			if _, ok := l.clients[name]; ok {
+				err = fmt.Errorf("duplicate client %q", name)
+				break
+			}
+			var key []byte
+			key, err = i2pB64enc.DecodeString(v)
+			if err == nil && len(key) != 32 {
+				err = fmt.Errorf("invalid key length %d", len(key))
+			}
+			l.clients[name] = ClientAuth{Name: name, ServerKey: key}
+		default:
+			err = fmt.Errorf("unknown key %q", k)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading client list: %w", err)
+	}
+	if l.Service == "" || (l.Type != ClientAuthDH && l.Type != ClientAuthPSK) {
+		return nil, errors.New("client list is missing the service or type")
+	}
+	for name, c := range l.clients {
+		c.Type = l.Type
+		l.clients[name] = c
+	}
+	return l, nil
+}
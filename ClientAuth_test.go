@@ -0,0 +1,143 @@
+package i2pkeys
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"strings"
+	"testing"
+)
+
+func Test_ClientAuth(t *testing.T) {
+	keys, err := NewKeysFromSeed(bytes.Repeat([]byte{0x44}, SeedSize))
+	if err != nil {
+		t.Fatalf("NewKeysFromSeed failed: %v", err)
+	}
+
+	t.Run("DH", func(t *testing.T) {
+		l, err := NewClientAuthList(keys, ClientAuthDH)
+		if err != nil {
+			t.Fatalf("NewClientAuthList failed: %v", err)
+		}
+		alice, err := l.Add("alice")
+		if err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+		priv, err := ecdh.X25519().NewPrivateKey(alice.ClientKey)
+		if err != nil {
+			t.Fatalf("Client key is not an X25519 key: %v", err)
+		}
+		if !bytes.Equal(priv.PublicKey().Bytes(), alice.ServerKey) {
+			t.Error("Server key is not the client's public key")
+		}
+		bobKey, _ := ecdh.X25519().GenerateKey(rand.Reader)
+		if err := l.AddPublicKey("bob", bobKey.PublicKey().Bytes()); err != nil {
+			t.Fatalf("AddPublicKey failed: %v", err)
+		}
+		if _, err := l.Add("alice"); err == nil {
+			t.Error("Add should have failed for duplicate client")
+		}
+		clients := l.Clients()
+		if len(clients) != 2 || clients[0].Name != "alice" || clients[1].Name != "bob" {
+			t.Fatalf("Unexpected clients %+v", clients)
+		}
+		if clients[0].ClientKey != nil {
+			t.Error("List should not hold client secrets")
+		}
+
+		opts := l.ServerOptions()
+		if opts["i2cp.leaseSetType"] != "5" || opts["i2cp.leaseSetAuthType"] != "1" {
+			t.Errorf("Unexpected server options %v", opts)
+		}
+		if opts["i2cp.leaseSetClient.dh.0"] != "alice:"+i2pB64enc.EncodeToString(alice.ServerKey) {
+			t.Errorf("Unexpected client option %q", opts["i2cp.leaseSetClient.dh.0"])
+		}
+		copts, err := alice.ClientOptions()
+		if err != nil || copts["i2cp.leaseSetPrivKey"] != i2pB64enc.EncodeToString(alice.ClientKey) {
+			t.Errorf("Unexpected client options %v, %v", copts, err)
+		}
+
+		var java, i2pd bytes.Buffer
+		if err := l.WriteJavaConfig(&java, 3); err != nil {
+			t.Fatalf("WriteJavaConfig failed: %v", err)
+		}
+		if !strings.Contains(java.String(), "tunnel.3.option.i2cp.leaseSetClient.dh.1=bob:") {
+			t.Errorf("Unexpected Java config:\n%s", java.String())
+		}
+		if err := l.WriteI2PDConfig(&i2pd); err != nil {
+			t.Fatalf("WriteI2PDConfig failed: %v", err)
+		}
+		if !strings.Contains(i2pd.String(), "i2cp.leaseSetAuthType = 1\n") {
+			t.Errorf("Unexpected i2pd config:\n%s", i2pd.String())
+		}
+
+		if err := l.Revoke("alice"); err != nil {
+			t.Fatalf("Revoke failed: %v", err)
+		}
+		if len(l.Clients()) != 1 || l.Revoke("alice") == nil {
+			t.Error("Revoke did not remove the client")
+		}
+
+		b33, err := l.BlindedAddress()
+		if err != nil || !b33.PerClientAuth {
+			t.Errorf("BlindedAddress should require client authorization: %v", err)
+		}
+	})
+
+	t.Run("PSK and storage", func(t *testing.T) {
+		l, err := NewClientAuthList(keys, ClientAuthPSK)
+		if err != nil {
+			t.Fatalf("NewClientAuthList failed: %v", err)
+		}
+		carol, err := l.Add("carol")
+		if err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+		if !bytes.Equal(carol.ClientKey, carol.ServerKey) {
+			t.Error("PSK client and server keys should match")
+		}
+		var buf bytes.Buffer
+		if err := StoreClientAuthList(l, &buf); err != nil {
+			t.Fatalf("StoreClientAuthList failed: %v", err)
+		}
+		loaded, err := LoadClientAuthList(&buf)
+		if err != nil {
+			t.Fatalf("LoadClientAuthList failed: %v", err)
+		}
+		if loaded.Service != keys.Addr() || loaded.Type != ClientAuthPSK {
+			t.Errorf("Loaded list has wrong service or type")
+		}
+		clients := loaded.Clients()
+		if len(clients) != 1 || clients[0].Name != "carol" || !bytes.Equal(clients[0].ServerKey, carol.ServerKey) || clients[0].Type != ClientAuthPSK {
+			t.Errorf("Loaded clients do not match: %+v", clients)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		l, _ := NewClientAuthList(keys, ClientAuthPSK)
+		if _, err := l.Add("bad:name"); err == nil {
+			t.Error("Add should have failed for name with colon")
+		}
+		if err := l.AddPublicKey("dave", make([]byte, 32)); err == nil {
+			t.Error("AddPublicKey should have failed for PSK list")
+		}
+		if _, err := NewClientAuthList(keys, ClientAuthNone); err == nil {
+			t.Error("NewClientAuthList should have failed without an authorization type")
+		}
+		header := "service=" + keys.Addr().Base64() + "\ntype=psk\nclient.erin=" + i2pB64enc.EncodeToString(make([]byte, 32)) + "\n"
+		if _, err := LoadClientAuthList(strings.NewReader(header)); err != nil {
+			t.Fatalf("LoadClientAuthList failed: %v", err)
+		}
+		for name, line := range map[string]string{
+			"Duplicate name": "client.erin=",
+			"Separator":      "client.frank:x=",
+			"Whitespace":     "client.frank =",
+			"Empty name":     "client.=",
+		} {
+			list := header + line + i2pB64enc.EncodeToString(make([]byte, 32))
+			if _, err := LoadClientAuthList(strings.NewReader(list)); err == nil {
+				t.Errorf("LoadClientAuthList should have failed: %s", name)
+			}
+		}
+	})
+}
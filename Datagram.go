@@ -0,0 +1,194 @@
+package i2pkeys
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// I2CP protocol numbers of the datagram formats, as used in the PROTOCOL
+// option of SAM RAW and DATAGRAM sessions.
+const (
+	ProtocolDatagram1 = 17
+	ProtocolRaw       = 18
+	ProtocolDatagram2 = 19
+	ProtocolDatagram3 = 20
+)
+
+const (
+	datagramFlagOptions uint16 = 1 << 4
+	datagramFlagOffline uint16 = 1 << 5
+	datagramVersionMask uint16 = 0x0f
+)
+
+// Datagram is a decoded repliable datagram.
+type Datagram struct {
+	Version          int     // 1, 2 or 3
+	From             I2PAddr // empty for Datagram3, which only carries FromHash
+	FromHash         I2PDestHash
+	Options          map[string]string
+	OfflineSignature *OfflineSignature // Datagram2 only
+	Payload          []byte
+}
+
+// SignDatagram1 builds a signed repliable datagram in the original format:
+// the sender's destination, a signature and the payload. Keys with an
+// offline signature cannot send this format, use SignDatagram2.
+func (k I2PKeys) SignDatagram1(payload []byte) ([]byte, error) {
+	p, err := k.privateKeyFile()
+	if err != nil {
+		return nil, err
+	}
+	if p.offline != nil {
+		return nil, errors.New("Datagram1 does not support offline signatures")
+	}
+	sig, err := signMessage(p.dest.sigType, p.signingPrivateKey, payload)
+	if err != nil {
+		return nil, err
+	}
+	b := append(append([]byte{}, p.dest.raw...), sig...)
+	return append(b, payload...), nil
+}
+
+// ReadDatagram1 decodes a Datagram1 and verifies its signature.
+func ReadDatagram1(b []byte) (*Datagram, error) {
+	kc, err := readKeysAndCert(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Datagram1: error reading sender: %w", err)
+	}
+	sig, payload, err := readBytes(b[len(kc.raw):], kc.sigType.SignatureLen(), "signature")
+	if err != nil {
+		return nil, fmt.Errorf("invalid Datagram1: %w", err)
+	}
+	if err := VerifySignature(kc.sigType, kc.signingKey, payload, sig); err != nil {
+		return nil, fmt.Errorf("Datagram1 signature: %w", err)
+	}
+	from := I2PAddr(i2pB64enc.EncodeToString(kc.raw))
+	return &Datagram{Version: 1, From: from, FromHash: from.DestHash(), Payload: payload}, nil
+}
+
+// appendDatagramHeader encodes the flags and options of a Datagram2 or
+// Datagram3.
+func appendDatagramHeader(b []byte, version, flags uint16, options map[string]string) ([]byte, error) {
+	if len(options) > 0 {
+		flags |= datagramFlagOptions
+	}
+	b = binary.BigEndian.AppendUint16(b, version|flags)
+	if len(options) > 0 {
+		return appendMapping(b, options)
+	}
+	return b, nil
+}
+
+// readDatagramHeader decodes the flags and options of a Datagram2 or
+// Datagram3.
+func readDatagramHeader(b []byte, d *Datagram) (uint16, []byte, error) {
+	f, rest, err := readBytes(b, 2, "flags")
+	if err != nil {
+		return 0, nil, err
+	}
+	flags := binary.BigEndian.Uint16(f)
+	if int(flags&datagramVersionMask) != d.Version {
+		return 0, nil, fmt.Errorf("version %d", flags&datagramVersionMask)
+	}
+	if flags&datagramFlagOptions != 0 {
+		if d.Options, rest, err = readMapping(rest); err != nil {
+			return 0, nil, err
+		}
+	}
+	return flags, rest, nil
+}
+
+// SignDatagram2 builds a signed repliable datagram in the Datagram2 format.
+// The signature also covers the hash of the receiving destination to, so
+// that the datagram cannot be replayed to other destinations. Keys with an
+// offline signature sign with their transient key.
+func (k I2PKeys) SignDatagram2(to I2PDestHash, payload []byte, options map[string]string) ([]byte, error) {
+	p, err := k.privateKeyFile()
+	if err != nil {
+		return nil, err
+	}
+	var flags uint16
+	if p.offline != nil {
+		flags |= datagramFlagOffline
+	}
+	signed, err := appendDatagramHeader(append([]byte{}, to[:]...), 2, flags, options)
+	if err != nil {
+		return nil, err
+	}
+	if p.offline != nil {
+		signed = append(signed, p.offline.Bytes()...)
+	}
+	signed = append(signed, payload...)
+	sigType, priv := p.signingKey()
+	sig, err := signMessage(sigType, priv, signed)
+	if err != nil {
+		return nil, err
+	}
+	b := append(append([]byte{}, p.dest.raw...), signed[len(to):]...)
+	return append(b, sig...), nil
+}
+
+// ReadDatagram2 decodes a Datagram2 received by the destination to and
+// verifies its signature, including the offline signature if present.
+func ReadDatagram2(b []byte, to I2PDestHash) (*Datagram, error) {
+	d := &Datagram{Version: 2}
+	kc, err := readKeysAndCert(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Datagram2: error reading sender: %w", err)
+	}
+	d.From = I2PAddr(i2pB64enc.EncodeToString(kc.raw))
+	d.FromHash = d.From.DestHash()
+	flags, rest, err := readDatagramHeader(b[len(kc.raw):], d)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Datagram2: %w", err)
+	}
+	sigType, key := kc.sigType, kc.signingKey
+	if flags&datagramFlagOffline != 0 {
+		o, r, err := ReadOfflineSignature(rest, kc.sigType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Datagram2: %w", err)
+		}
+		if err := o.Verify(d.From); err != nil {
+			return nil, fmt.Errorf("Datagram2 %w", err)
+		}
+		d.OfflineSignature, rest = &o, r
+		sigType, key = o.TransientType, o.TransientPublicKey
+	}
+	if sigType.SignatureLen() == 0 || len(rest) < sigType.SignatureLen() {
+		return nil, errors.New("invalid Datagram2: signature truncated")
+	}
+	d.Payload = rest[:len(rest)-sigType.SignatureLen()]
+	sig := rest[len(d.Payload):]
+	signed := append(append([]byte{}, to[:]...), b[len(kc.raw):len(b)-len(sig)]...)
+	if err := VerifySignature(sigType, key, signed, sig); err != nil {
+		return nil, fmt.Errorf("Datagram2 signature: %w", err)
+	}
+	return d, nil
+}
+
+// NewDatagram3 builds an unsigned repliable datagram in the Datagram3
+// format, which only carries the hash of the sender. The receiver must look
+// up the sender's LeaseSet to reply, and cannot authenticate it.
+func (k I2PKeys) NewDatagram3(payload []byte, options map[string]string) ([]byte, error) {
+	from := k.Address.DestHash()
+	b, err := appendDatagramHeader(append([]byte{}, from[:]...), 3, 0, options)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, payload...), nil
+}
+
+// ReadDatagram3 decodes a Datagram3.
+func ReadDatagram3(b []byte) (*Datagram, error) {
+	d := &Datagram{Version: 3}
+	h, rest, err := readBytes(b, 32, "sender hash")
+	if err != nil {
+		return nil, fmt.Errorf("invalid Datagram3: %w", err)
+	}
+	d.FromHash = I2PDestHash(h)
+	if _, d.Payload, err = readDatagramHeader(rest, d); err != nil {
+		return nil, fmt.Errorf("invalid Datagram3: %w", err)
+	}
+	return d, nil
+}
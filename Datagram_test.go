@@ -0,0 +1,94 @@
+package i2pkeys
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func Test_Datagram(t *testing.T) {
+	sender, err := GenerateDestination(SigTypeEd25519, CryptoTypeX25519)
+	if err != nil {
+		t.Fatalf("GenerateDestination failed: %v", err)
+	}
+	receiver, _ := GenerateDestination(SigTypeEd25519, CryptoTypeX25519)
+	to := receiver.Addr().DestHash()
+	payload := []byte("hello over I2P")
+
+	t.Run("Datagram1", func(t *testing.T) {
+		b, err := sender.SignDatagram1(payload)
+		if err != nil {
+			t.Fatalf("SignDatagram1 failed: %v", err)
+		}
+		d, err := ReadDatagram1(b)
+		if err != nil {
+			t.Fatalf("ReadDatagram1 failed: %v", err)
+		}
+		if d.From != sender.Addr() || !bytes.Equal(d.Payload, payload) {
+			t.Errorf("Unexpected datagram %+v", d)
+		}
+		b[len(b)-1] ^= 1
+		if _, err := ReadDatagram1(b); err == nil {
+			t.Error("ReadDatagram1 should have failed for a modified payload")
+		}
+	})
+
+	t.Run("Datagram2", func(t *testing.T) {
+		opts := map[string]string{"a": "b"}
+		b, err := sender.SignDatagram2(to, payload, opts)
+		if err != nil {
+			t.Fatalf("SignDatagram2 failed: %v", err)
+		}
+		d, err := ReadDatagram2(b, to)
+		if err != nil {
+			t.Fatalf("ReadDatagram2 failed: %v", err)
+		}
+		if d.From != sender.Addr() || !bytes.Equal(d.Payload, payload) || d.Options["a"] != "b" {
+			t.Errorf("Unexpected datagram %+v", d)
+		}
+		if _, err := ReadDatagram2(b, sender.Addr().DestHash()); err == nil {
+			t.Error("ReadDatagram2 should have failed for another receiver")
+		}
+		if _, err := ReadDatagram1(b); err == nil {
+			t.Error("ReadDatagram1 should have failed for a Datagram2")
+		}
+	})
+
+	t.Run("Datagram2 with offline keys", func(t *testing.T) {
+		transient, err := sender.NewOfflineKeys(SigTypeECDSASHA256P256, time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatalf("NewOfflineKeys failed: %v", err)
+		}
+		if _, err := transient.SignDatagram1(payload); err == nil {
+			t.Error("SignDatagram1 should have failed for offline keys")
+		}
+		b, err := transient.SignDatagram2(to, payload, nil)
+		if err != nil {
+			t.Fatalf("SignDatagram2 failed: %v", err)
+		}
+		d, err := ReadDatagram2(b, to)
+		if err != nil {
+			t.Fatalf("ReadDatagram2 failed: %v", err)
+		}
+		if d.OfflineSignature == nil || d.From != sender.Addr() || !bytes.Equal(d.Payload, payload) {
+			t.Errorf("Unexpected datagram %+v", d)
+		}
+	})
+
+	t.Run("Datagram3", func(t *testing.T) {
+		b, err := sender.NewDatagram3(payload, nil)
+		if err != nil {
+			t.Fatalf("NewDatagram3 failed: %v", err)
+		}
+		d, err := ReadDatagram3(b)
+		if err != nil {
+			t.Fatalf("ReadDatagram3 failed: %v", err)
+		}
+		if d.FromHash != sender.Addr().DestHash() || d.From != "" || !bytes.Equal(d.Payload, payload) {
+			t.Errorf("Unexpected datagram %+v", d)
+		}
+		if _, err := ReadDatagram3(b[:33]); err == nil {
+			t.Error("ReadDatagram3 should have failed for a truncated datagram")
+		}
+	})
+}
@@ -0,0 +1,320 @@
+package i2pkeys
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// SigType identifies the signing algorithm of a destination. It is carried in
+// the key certificate of the destination, destinations with a NULL
+// certificate are always DSA_SHA1.
+type SigType uint16
+
+const (
+	SigTypeDSASHA1         SigType = 0
+	SigTypeECDSASHA256P256 SigType = 1
+	SigTypeECDSASHA384P384 SigType = 2
+	SigTypeECDSASHA512P521 SigType = 3
+	SigTypeRSASHA2562048   SigType = 4
+	SigTypeRSASHA3843072   SigType = 5
+	SigTypeRSASHA5124096   SigType = 6
+	SigTypeEd25519         SigType = 7
+	SigTypeEd25519ph       SigType = 8
+	SigTypeRedDSAEd25519   SigType = 11
+)
+
+// CryptoType identifies the encryption algorithm of a destination's public
+// key. Destinations with a NULL certificate are always ElGamal.
+type CryptoType uint16
+
+const (
+	CryptoTypeElGamal CryptoType = 0
+	CryptoTypeP256    CryptoType = 1
+	CryptoTypeP384    CryptoType = 2
+	CryptoTypeP521    CryptoType = 3
+	CryptoTypeX25519  CryptoType = 4
+
+	// Post-quantum hybrid types, for LeaseSets only. The published key is
+	// the X25519 part, the ML-KEM keys are exchanged during handshakes.
+	CryptoTypeMLKEM512X25519  CryptoType = 5
+	CryptoTypeMLKEM768X25519  CryptoType = 6
+	CryptoTypeMLKEM1024X25519 CryptoType = 7
+
+	// ML-KEM keys as used in handshakes only.
+	CryptoTypeMLKEM512  CryptoType = 8
+	CryptoTypeMLKEM768  CryptoType = 9
+	CryptoTypeMLKEM1024 CryptoType = 10
+)
+
+type keyTypeInfo struct {
+	name    string
+	pubLen  int
+	privLen int
+	sigLen  int
+}
+
+var sigTypes = map[SigType]keyTypeInfo{
+	SigTypeDSASHA1:         {"DSA_SHA1", 128, 20, 40},
+	SigTypeECDSASHA256P256: {"ECDSA_SHA256_P256", 64, 32, 64},
+	SigTypeECDSASHA384P384: {"ECDSA_SHA384_P384", 96, 48, 96},
+	SigTypeECDSASHA512P521: {"ECDSA_SHA512_P521", 132, 66, 132},
+	SigTypeRSASHA2562048:   {"RSA_SHA256_2048", 256, 512, 256},
+	SigTypeRSASHA3843072:   {"RSA_SHA384_3072", 384, 768, 384},
+	SigTypeRSASHA5124096:   {"RSA_SHA512_4096", 512, 1024, 512},
+	SigTypeEd25519:         {"EdDSA_SHA512_Ed25519", 32, 32, 64},
+	SigTypeEd25519ph:       {"EdDSA_SHA512_Ed25519ph", 32, 32, 64},
+	SigTypeRedDSAEd25519:   {"RedDSA_SHA512_Ed25519", 32, 32, 64},
+}
+
+var cryptoTypes = map[CryptoType]keyTypeInfo{
+	CryptoTypeElGamal: {"ELGAMAL_2048", 256, 256, 0},
+	CryptoTypeP256:    {"EC_P256", 64, 32, 0},
+	CryptoTypeP384:    {"EC_P384", 96, 48, 0},
+	CryptoTypeP521:    {"EC_P521", 132, 66, 0},
+	CryptoTypeX25519:  {"ECIES_X25519", 32, 32, 0},
+
+	CryptoTypeMLKEM512X25519:  {"MLKEM512_X25519", 32, 32, 0},
+	CryptoTypeMLKEM768X25519:  {"MLKEM768_X25519", 32, 32, 0},
+	CryptoTypeMLKEM1024X25519: {"MLKEM1024_X25519", 32, 32, 0},
+	CryptoTypeMLKEM512:        {"MLKEM512", 800, 1632, 0},
+	CryptoTypeMLKEM768:        {"MLKEM768", 1184, 2400, 0},
+	CryptoTypeMLKEM1024:       {"MLKEM1024", 1568, 3168, 0},
+}
+
+// Returns the name I2P uses for the signature type, e.g. EdDSA_SHA512_Ed25519
+func (t SigType) String() string {
+	if info, ok := sigTypes[t]; ok {
+		return info.name
+	}
+	return "SigType(" + strconv.Itoa(int(t)) + ")"
+}
+
+// Returns the length of a signing public key of this type, or 0 if the type
+// is unknown.
+func (t SigType) PublicKeyLen() int {
+	return sigTypes[t].pubLen
+}
+
+// Returns the length of a signing private key of this type, or 0 if the type
+// is unknown.
+func (t SigType) PrivateKeyLen() int {
+	return sigTypes[t].privLen
+}
+
+// Returns the length of a signature of this type, or 0 if the type is unknown.
+func (t SigType) SignatureLen() int {
+	return sigTypes[t].sigLen
+}
+
+// Returns the name I2P uses for the encryption type, e.g. ECIES_X25519
+func (t CryptoType) String() string {
+	if info, ok := cryptoTypes[t]; ok {
+		return info.name
+	}
+	return "CryptoType(" + strconv.Itoa(int(t)) + ")"
+}
+
+// Returns the length of an encryption public key of this type, or 0 if the
+// type is unknown.
+func (t CryptoType) PublicKeyLen() int {
+	return cryptoTypes[t].pubLen
+}
+
+// Returns the length of an encryption private key of this type, or 0 if the
+// type is unknown.
+func (t CryptoType) PrivateKeyLen() int {
+	return cryptoTypes[t].privLen
+}
+
+// identityType reports whether the type may appear in a destination or router
+// identity. The post-quantum types are limited to LeaseSets and handshakes.
+func (t CryptoType) identityType() bool {
+	return t <= CryptoTypeX25519
+}
+
+const (
+	certTypeNull byte = 0
+	certTypeKey  byte = 5
+
+	pubKeyFieldLen  = 256
+	sigKeyFieldLen  = 128
+	keysFieldLen    = pubKeyFieldLen + sigKeyFieldLen
+	certHeaderLen   = 3
+	minKeysAndCert  = keysFieldLen + certHeaderLen
+	keyCertMinBytes = 4
+)
+
+// keysAndCert is the decoded form of the KeysAndCert structure shared by
+// destinations and router identities.
+type keysAndCert struct {
+	raw        []byte
+	cryptoType CryptoType
+	sigType    SigType
+	cryptoKey  []byte
+	signingKey []byte
+	certType   byte
+	cert       []byte
+}
+
+// readKeysAndCert decodes the KeysAndCert at the start of b. The returned
+// structure references b, the number of bytes it occupies is len(kc.raw).
+func readKeysAndCert(b []byte) (kc keysAndCert, err error) {
+	if len(b) < minKeysAndCert {
+		return kc, fmt.Errorf("keys and cert too short: %d bytes", len(b))
+	}
+	kc.certType = b[keysFieldLen]
+	certLen := int(binary.BigEndian.Uint16(b[keysFieldLen+1:]))
+	end := minKeysAndCert + certLen
+	if len(b) < end {
+		return kc, fmt.Errorf("certificate truncated: need %d bytes, have %d", end, len(b))
+	}
+	kc.raw = b[:end]
+	kc.cert = b[minKeysAndCert:end]
+	kc.cryptoType = CryptoTypeElGamal
+	kc.sigType = SigTypeDSASHA1
+	if kc.certType == certTypeKey {
+		if certLen < keyCertMinBytes {
+			return kc, errors.New("key certificate too short")
+		}
+		kc.sigType = SigType(binary.BigEndian.Uint16(kc.cert[0:2]))
+		kc.cryptoType = CryptoType(binary.BigEndian.Uint16(kc.cert[2:4]))
+	}
+	sigLen := kc.sigType.PublicKeyLen()
+	if sigLen == 0 {
+		return kc, fmt.Errorf("unsupported signature type %s", kc.sigType)
+	}
+	cryptoLen := kc.cryptoType.PublicKeyLen()
+	if cryptoLen == 0 || !kc.cryptoType.identityType() {
+		return kc, fmt.Errorf("unsupported encryption type %s", kc.cryptoType)
+	}
+	// Keys which do not fit in their field spill over into the key
+	// certificate, signing key first.
+	var excess []byte
+	if len(kc.cert) > keyCertMinBytes {
+		excess = kc.cert[keyCertMinBytes:]
+	}
+	if sigLen <= sigKeyFieldLen {
+		kc.signingKey = b[keysFieldLen-sigLen : keysFieldLen]
+	} else {
+		extra := sigLen - sigKeyFieldLen
+		if len(excess) < extra {
+			return kc, errors.New("key certificate missing signing key data")
+		}
+		kc.signingKey = append(append([]byte{}, b[pubKeyFieldLen:keysFieldLen]...), excess[:extra]...)
+		excess = excess[extra:]
+	}
+	if cryptoLen <= pubKeyFieldLen {
+		kc.cryptoKey = b[:cryptoLen]
+	} else {
+		extra := cryptoLen - pubKeyFieldLen
+		if len(excess) < extra {
+			return kc, errors.New("key certificate missing encryption key data")
+		}
+		kc.cryptoKey = append(append([]byte{}, b[:pubKeyFieldLen]...), excess[:extra]...)
+	}
+	return kc, nil
+}
+
+// newKeysAndCert serializes a KeysAndCert structure. Unused space between the
+// two keys is filled by repeating padding, which must not be empty when there
+// is space to fill. DSA_SHA1 with ElGamal is written with a NULL certificate,
+// everything else gets a key certificate.
+func newKeysAndCert(cryptoType CryptoType, cryptoKey []byte, sigType SigType, signingKey []byte, padding []byte) ([]byte, error) {
+	cryptoLen := cryptoType.PublicKeyLen()
+	if !cryptoType.identityType() {
+		return nil, fmt.Errorf("%s keys cannot be used in destinations", cryptoType)
+	}
+	if cryptoLen == 0 || len(cryptoKey) != cryptoLen {
+		return nil, fmt.Errorf("invalid %s public key length %d", cryptoType, len(cryptoKey))
+	}
+	sigLen := sigType.PublicKeyLen()
+	if sigLen == 0 || len(signingKey) != sigLen {
+		return nil, fmt.Errorf("invalid %s public key length %d", sigType, len(signingKey))
+	}
+	padStart, padEnd := cryptoLen, keysFieldLen-sigLen
+	if padStart > pubKeyFieldLen {
+		padStart = pubKeyFieldLen
+	}
+	if padEnd < pubKeyFieldLen {
+		padEnd = pubKeyFieldLen
+	}
+	buf := make([]byte, keysFieldLen, keysFieldLen+certHeaderLen+keyCertMinBytes)
+	copy(buf, cryptoKey[:padStart])
+	var excess []byte
+	if sigLen <= sigKeyFieldLen {
+		copy(buf[keysFieldLen-sigLen:], signingKey)
+	} else {
+		copy(buf[pubKeyFieldLen:], signingKey[:sigKeyFieldLen])
+		excess = append(excess, signingKey[sigKeyFieldLen:]...)
+	}
+	if cryptoLen > pubKeyFieldLen {
+		excess = append(excess, cryptoKey[pubKeyFieldLen:]...)
+	}
+	if padEnd > padStart {
+		if len(padding) == 0 {
+			return nil, errors.New("padding required")
+		}
+		for i := padStart; i < padEnd; i++ {
+			buf[i] = padding[(i-padStart)%len(padding)]
+		}
+	}
+	if sigType == SigTypeDSASHA1 && cryptoType == CryptoTypeElGamal {
+		return append(buf, certTypeNull, 0, 0), nil
+	}
+	cert := make([]byte, certHeaderLen+keyCertMinBytes)
+	cert[0] = certTypeKey
+	binary.BigEndian.PutUint16(cert[1:], uint16(keyCertMinBytes+len(excess)))
+	binary.BigEndian.PutUint16(cert[3:], uint16(sigType))
+	binary.BigEndian.PutUint16(cert[5:], uint16(cryptoType))
+	buf = append(buf, cert...)
+	return append(buf, excess...), nil
+}
+
+func (addr I2PAddr) keysAndCert() (keysAndCert, error) {
+	b, err := addr.ToBytes()
+	if err != nil {
+		return keysAndCert{}, fmt.Errorf("error decoding destination: %w", err)
+	}
+	kc, err := readKeysAndCert(b)
+	if err != nil {
+		return kc, err
+	}
+	if len(kc.raw) != len(b) {
+		return kc, fmt.Errorf("trailing data after destination: %d bytes", len(b)-len(kc.raw))
+	}
+	return kc, nil
+}
+
+// Returns the signature type from the destination's certificate.
+func (addr I2PAddr) SigType() (SigType, error) {
+	kc, err := addr.keysAndCert()
+	return kc.sigType, err
+}
+
+// Returns the encryption type from the destination's certificate.
+func (addr I2PAddr) CryptoType() (CryptoType, error) {
+	kc, err := addr.keysAndCert()
+	return kc.cryptoType, err
+}
+
+// Returns the signing public key of the destination, reassembled from the
+// certificate if it did not fit into the key field.
+func (addr I2PAddr) SigningPublicKey() ([]byte, error) {
+	kc, err := addr.keysAndCert()
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{}, kc.signingKey...), nil
+}
+
+// Returns the encryption public key of the destination, reassembled from the
+// certificate if it did not fit into the key field.
+func (addr I2PAddr) EncryptionPublicKey() ([]byte, error) {
+	kc, err := addr.keysAndCert()
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{}, kc.cryptoKey...), nil
+}
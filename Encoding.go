@@ -0,0 +1,285 @@
+package i2pkeys
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrKeysRedacted is returned when unmarshalling into I2PKeys, which are
+// only ever marshalled without their private keys. Use ExportedKeys to store
+// private keys.
+var ErrKeysRedacted = errors.New("I2PKeys are marshalled without private keys, use ExportedKeys")
+
+// unmarshalJSONString decodes a JSON string, treating null as empty.
+func unmarshalJSONString(b []byte) (string, error) {
+	if string(b) == "null" {
+		return "", nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return "", err
+	}
+	return s, nil
+}
+
+// scanText converts a database value to text.
+func scanText(src any) (string, error) {
+	switch v := src.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	}
+	return "", fmt.Errorf("cannot scan %T", src)
+}
+
+// MarshalText returns the base64 destination.
+func (a I2PAddr) MarshalText() ([]byte, error) {
+	return []byte(a.Base64()), nil
+}
+
+// UnmarshalText parses a base64 destination with NewI2PAddrFromString. An
+// empty text gives an empty I2PAddr.
+func (a *I2PAddr) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*a = ""
+		return nil
+	}
+	addr, err := NewI2PAddrFromString(string(text))
+	if err != nil {
+		return err
+	}
+	*a = addr
+	return nil
+}
+
+// MarshalBinary returns the binary destination.
+func (a I2PAddr) MarshalBinary() ([]byte, error) {
+	return a.ToBytes()
+}
+
+// UnmarshalBinary parses a binary destination with NewI2PAddrFromBytes.
+func (a *I2PAddr) UnmarshalBinary(data []byte) error {
+	addr, err := NewI2PAddrFromBytes(data)
+	if err != nil {
+		return err
+	}
+	*a = addr
+	return nil
+}
+
+// MarshalJSON returns the base64 destination as a JSON string.
+func (a I2PAddr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.Base64())
+}
+
+// UnmarshalJSON parses a JSON string like UnmarshalText.
+func (a *I2PAddr) UnmarshalJSON(b []byte) error {
+	s, err := unmarshalJSONString(b)
+	if err != nil {
+		return err
+	}
+	return a.UnmarshalText([]byte(s))
+}
+
+// Value stores the base64 destination in a database, or NULL if empty.
+func (a I2PAddr) Value() (driver.Value, error) {
+	if a == "" {
+		return nil, nil
+	}
+	return a.Base64(), nil
+}
+
+// Scan reads a base64 destination from a database.
+func (a *I2PAddr) Scan(src any) error {
+	s, err := scanText(src)
+	if err != nil {
+		return err
+	}
+	return a.UnmarshalText([]byte(s))
+}
+
+// MarshalText returns the .b32.i2p address.
+func (h I2PDestHash) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+// UnmarshalText parses a .b32.i2p address with DestHashFromString. An empty
+// text gives the zero hash.
+func (h *I2PDestHash) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*h = I2PDestHash{}
+		return nil
+	}
+	d, err := DestHashFromString(strings.TrimSpace(string(text)))
+	if err != nil {
+		return err
+	}
+	*h = d
+	return nil
+}
+
+// MarshalBinary returns the 32 byte hash.
+func (h I2PDestHash) MarshalBinary() ([]byte, error) {
+	return append([]byte{}, h[:]...), nil
+}
+
+// UnmarshalBinary reads a 32 byte hash with DestHashFromBytes.
+func (h *I2PDestHash) UnmarshalBinary(data []byte) error {
+	d, err := DestHashFromBytes(data)
+	if err != nil {
+		return err
+	}
+	*h = d
+	return nil
+}
+
+// MarshalJSON returns the .b32.i2p address as a JSON string.
+func (h I2PDestHash) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.String())
+}
+
+// UnmarshalJSON parses a .b32.i2p address from a JSON string.
+func (h *I2PDestHash) UnmarshalJSON(b []byte) error {
+	s, err := unmarshalJSONString(b)
+	if err != nil {
+		return err
+	}
+	return h.UnmarshalText([]byte(s))
+}
+
+// Value stores the .b32.i2p address in a database, or NULL for the zero
+// hash.
+func (h I2PDestHash) Value() (driver.Value, error) {
+	if h == (I2PDestHash{}) {
+		return nil, nil
+	}
+	return h.String(), nil
+}
+
+// Scan reads a .b32.i2p address, or a raw 32 byte hash from a binary
+// column.
+func (h *I2PDestHash) Scan(src any) error {
+	if b, ok := src.([]byte); ok && len(b) == 32 {
+		return h.UnmarshalBinary(b)
+	}
+	s, err := scanText(src)
+	if err != nil {
+		return err
+	}
+	return h.UnmarshalText([]byte(s))
+}
+
+// MarshalText returns only the base64 destination of the keys, so that
+// private keys never end up in configuration files or logs by accident.
+// Convert the keys to ExportedKeys to marshal the private keys.
+func (k I2PKeys) MarshalText() ([]byte, error) {
+	return k.Address.MarshalText()
+}
+
+// UnmarshalText always fails with ErrKeysRedacted, as the text form of
+// I2PKeys holds no private keys.
+func (k *I2PKeys) UnmarshalText(text []byte) error {
+	return ErrKeysRedacted
+}
+
+// ExportedKeys is I2PKeys with opt-in marshalling of the private keys, in
+// the SAM base64 format as text and JSON and in the binary PrivateKeyFile
+// format otherwise. Use it for the fields of configuration structs and
+// database models which must hold the keys:
+//
+//	cfg.Keys = i2pkeys.ExportedKeys(keys)
+type ExportedKeys I2PKeys
+
+// Keys returns the I2PKeys.
+func (e ExportedKeys) Keys() I2PKeys {
+	return I2PKeys(e)
+}
+
+// MarshalText returns the private keys in the base64 format used by SAM.
+func (e ExportedKeys) MarshalText() ([]byte, error) {
+	if e.Both == "" {
+		return []byte{}, nil
+	}
+	if _, err := I2PKeys(e).privateKeyFile(); err != nil {
+		return nil, err
+	}
+	return []byte(e.Both), nil
+}
+
+// UnmarshalText parses keys in the base64 format used by SAM and validates
+// them. An empty text gives empty keys.
+func (e *ExportedKeys) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*e = ExportedKeys{}
+		return nil
+	}
+	b, err := i2pB64enc.DecodeString(strings.TrimSpace(string(text)))
+	if err != nil {
+		return fmt.Errorf("error decoding private keys: %w", err)
+	}
+	return e.UnmarshalBinary(b)
+}
+
+// MarshalBinary returns the private keys in the PrivateKeyFile format.
+func (e ExportedKeys) MarshalBinary() ([]byte, error) {
+	p, err := I2PKeys(e).privateKeyFile()
+	if err != nil {
+		return nil, err
+	}
+	return p.bytes(), nil
+}
+
+// UnmarshalBinary parses and validates keys in the PrivateKeyFile format.
+func (e *ExportedKeys) UnmarshalBinary(data []byte) error {
+	p, err := readPrivateKeyFile(data)
+	if err != nil {
+		return fmt.Errorf("invalid private keys: %w", err)
+	}
+	*e = ExportedKeys(p.keys())
+	return nil
+}
+
+// MarshalJSON returns the private keys as a JSON string.
+func (e ExportedKeys) MarshalJSON() ([]byte, error) {
+	text, err := e.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON parses the private keys from a JSON string.
+func (e *ExportedKeys) UnmarshalJSON(b []byte) error {
+	s, err := unmarshalJSONString(b)
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalText([]byte(s))
+}
+
+// Value stores the private keys in a database in base64, or NULL if empty.
+func (e ExportedKeys) Value() (driver.Value, error) {
+	if e.Both == "" {
+		return nil, nil
+	}
+	text, err := e.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(text), nil
+}
+
+// Scan reads private keys stored by Value.
+func (e *ExportedKeys) Scan(src any) error {
+	s, err := scanText(src)
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalText([]byte(s))
+}
@@ -0,0 +1,136 @@
+package i2pkeys
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var (
+	_ encoding.TextMarshaler     = I2PAddr("")
+	_ encoding.TextUnmarshaler   = (*I2PAddr)(nil)
+	_ encoding.BinaryMarshaler   = I2PAddr("")
+	_ encoding.BinaryUnmarshaler = (*I2PAddr)(nil)
+	_ json.Marshaler             = I2PAddr("")
+	_ json.Unmarshaler           = (*I2PAddr)(nil)
+	_ driver.Valuer              = I2PAddr("")
+	_ sql.Scanner                = (*I2PAddr)(nil)
+	_ encoding.TextMarshaler     = I2PDestHash{}
+	_ encoding.BinaryUnmarshaler = (*I2PDestHash)(nil)
+	_ json.Unmarshaler           = (*I2PDestHash)(nil)
+	_ sql.Scanner                = (*I2PDestHash)(nil)
+	_ encoding.TextMarshaler     = I2PKeys{}
+	_ encoding.BinaryMarshaler   = ExportedKeys{}
+	_ json.Marshaler             = ExportedKeys{}
+	_ sql.Scanner                = (*ExportedKeys)(nil)
+)
+
+func Test_Encoding(t *testing.T) {
+	keys, err := GenerateDestination(SigTypeEd25519, CryptoTypeX25519)
+	if err != nil {
+		t.Fatalf("GenerateDestination failed: %v", err)
+	}
+
+	t.Run("JSON config", func(t *testing.T) {
+		type config struct {
+			Peer   I2PAddr
+			Hash   I2PDestHash
+			Keys   I2PKeys
+			Secret ExportedKeys
+		}
+		in := config{Peer: keys.Addr(), Hash: keys.Addr().DestHash(), Keys: keys, Secret: ExportedKeys(keys)}
+		b, err := json.Marshal(in)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		var raw map[string]string
+		if err := json.Unmarshal(b, &raw); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		if raw["Peer"] != keys.Addr().Base64() || raw["Hash"] != keys.Addr().Base32() {
+			t.Errorf("Unexpected encoding %s", b)
+		}
+		if raw["Keys"] != keys.Addr().Base64() {
+			t.Errorf("I2PKeys were not redacted: %s", raw["Keys"])
+		}
+		if raw["Secret"] != keys.Both {
+			t.Errorf("ExportedKeys not marshalled: %s", raw["Secret"])
+		}
+
+		var out config
+		if err := json.Unmarshal(b, &out); !errors.Is(err, ErrKeysRedacted) {
+			t.Errorf("Expected ErrKeysRedacted, got %v", err)
+		}
+		delete(raw, "Keys")
+		b, _ = json.Marshal(raw)
+		if err := json.Unmarshal(b, &out); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		if out.Peer != in.Peer || out.Hash != in.Hash || out.Secret != in.Secret {
+			t.Errorf("Round trip failed: %+v", out)
+		}
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		var addr I2PAddr
+		if err := json.Unmarshal([]byte(`"not a destination"`), &addr); err == nil {
+			t.Error("Unmarshal should have failed for an invalid I2PAddr")
+		}
+		var hash I2PDestHash
+		if err := hash.UnmarshalText([]byte("abc.b32.i2p")); err == nil {
+			t.Error("UnmarshalText should have failed for an invalid I2PDestHash")
+		}
+		var secret ExportedKeys
+		if err := secret.UnmarshalText([]byte(keys.Both[:len(keys.Both)-8])); err == nil {
+			t.Error("UnmarshalText should have failed for truncated keys")
+		}
+		if err := json.Unmarshal([]byte("null"), &addr); err != nil || addr != "" {
+			t.Errorf("null should give an empty I2PAddr: %v", err)
+		}
+	})
+
+	t.Run("Binary", func(t *testing.T) {
+		b, _ := keys.Addr().MarshalBinary()
+		var addr I2PAddr
+		if err := addr.UnmarshalBinary(b); err != nil || addr != keys.Addr() {
+			t.Errorf("I2PAddr binary round trip failed: %v", err)
+		}
+		b, _ = ExportedKeys(keys).MarshalBinary()
+		var secret ExportedKeys
+		if err := secret.UnmarshalBinary(b); err != nil || secret.Keys() != keys {
+			t.Errorf("ExportedKeys binary round trip failed: %v", err)
+		}
+	})
+
+	t.Run("SQL", func(t *testing.T) {
+		v, _ := keys.Addr().DestHash().Value()
+		var hash I2PDestHash
+		if err := hash.Scan([]byte(v.(string))); err != nil || hash != keys.Addr().DestHash() {
+			t.Errorf("Scan of text hash failed: %v", err)
+		}
+		h := keys.Addr().DestHash()
+		if err := hash.Scan(h[:]); err != nil || hash != h {
+			t.Errorf("Scan of binary hash failed: %v", err)
+		}
+		v, _ = ExportedKeys(keys).Value()
+		var secret ExportedKeys
+		if err := secret.Scan(v); err != nil || secret.Keys() != keys {
+			t.Errorf("Scan of keys failed: %v", err)
+		}
+		var null I2PDestHash
+		if err := null.Scan(nil); err != nil {
+			t.Errorf("Scan of NULL failed: %v", err)
+		}
+		if v, err := null.Value(); v != nil || err != nil {
+			t.Errorf("Value of the zero hash = %v, %v, want NULL", v, err)
+		}
+		var addr I2PAddr
+		if err := addr.Scan(42); err == nil || !strings.Contains(err.Error(), "int") {
+			t.Errorf("Scan of an int should have failed, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,247 @@
+package i2pkeys
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/cloudflare/circl/kem"
+	"github.com/cloudflare/circl/kem/mlkem/mlkem1024"
+	"github.com/cloudflare/circl/kem/mlkem/mlkem512"
+	"github.com/cloudflare/circl/kem/mlkem/mlkem768"
+)
+
+// ErrUnsupportedCryptoType is returned when an operation is not implemented
+// for an encryption type.
+var ErrUnsupportedCryptoType = errors.New("unsupported encryption type")
+
+// I2P's ElGamal group is the 2048 bit MODP group of RFC 3526 with generator 2.
+var (
+	elgamalP, _ = new(big.Int).SetString(
+		"FFFFFFFFFFFFFFFFC90FDAA22168C234C4C6628B80DC1CD1"+
+			"29024E088A67CC74020BBEA63B139B22514A08798E3404DD"+
+			"EF9519B3CD3A431B302B0A6DF25F14374FE1356D6D51C245"+
+			"E485B576625E7EC6F44C42E9A637ED6B0BFF5CB6F406B7ED"+
+			"EE386BFB5A899FA5AE9F24117C4B1FE649286651ECE45B3D"+
+			"C2007CB8A163BF0598DA48361C55D39A69163FA8FD24CF5F"+
+			"83655D23DCA3AD961C62F356208552BB9ED529077096966D"+
+			"670C354E4ABC9804F1746C08CA18217C32905E462E36CE3B"+
+			"E39E772C180E86039B2783A2EC07A28FB5C55DF06F4C52C9"+
+			"DE2BCBF6955817183995497CEA956AE515D2261898FA0510"+
+			"15728E5A8AACAA68FFFFFFFFFFFFFFFF", 16)
+	elgamalG = big.NewInt(2)
+)
+
+// EncryptionKeyPair is an encryption key of a destination, router or
+// LeaseSet, in the raw encoding and at the length the spec mandates for its
+// type.
+type EncryptionKeyPair struct {
+	Type       CryptoType
+	PublicKey  []byte
+	PrivateKey []byte
+}
+
+func ecdhCurve(t CryptoType) (ecdh.Curve, bool) {
+	switch t {
+	case CryptoTypeP256:
+		return ecdh.P256(), true
+	case CryptoTypeP384:
+		return ecdh.P384(), true
+	case CryptoTypeP521:
+		return ecdh.P521(), true
+	case CryptoTypeX25519, CryptoTypeMLKEM512X25519, CryptoTypeMLKEM768X25519, CryptoTypeMLKEM1024X25519:
+		return ecdh.X25519(), true
+	}
+	return nil, false
+}
+
+// ecdhPublicBytes strips the uncompressed point prefix of NIST curve keys,
+// I2P stores only X || Y.
+func ecdhPublicBytes(t CryptoType, pub *ecdh.PublicKey) []byte {
+	b := pub.Bytes()
+	if len(b) == t.PublicKeyLen()+1 {
+		return b[1:]
+	}
+	return b
+}
+
+// GenerateEncryptionKey creates a new encryption key pair. For the hybrid
+// ML-KEM types the pair is the X25519 key published in LeaseSets, see
+// GenerateMLKEMKey for the ML-KEM part.
+func GenerateEncryptionKey(t CryptoType) (EncryptionKeyPair, error) {
+	log.WithField("type", t).Debug("Generating encryption key")
+	if t == CryptoTypeElGamal {
+		max := new(big.Int).Sub(elgamalP, big.NewInt(3))
+		x, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return EncryptionKeyPair{}, fmt.Errorf("error generating key: %w", err)
+		}
+		x.Add(x, big.NewInt(2))
+		return NewEncryptionKeyPair(t, x.FillBytes(make([]byte, t.PrivateKeyLen())))
+	}
+	curve, ok := ecdhCurve(t)
+	if !ok {
+		return EncryptionKeyPair{}, fmt.Errorf("%w: %s", ErrUnsupportedCryptoType, t)
+	}
+	priv, err := curve.GenerateKey(rand.Reader)
+	if err != nil {
+		return EncryptionKeyPair{}, fmt.Errorf("error generating key: %w", err)
+	}
+	return EncryptionKeyPair{Type: t, PublicKey: ecdhPublicBytes(t, priv.PublicKey()), PrivateKey: priv.Bytes()}, nil
+}
+
+// NewEncryptionKeyPair restores a key pair from its private key.
+func NewEncryptionKeyPair(t CryptoType, priv []byte) (EncryptionKeyPair, error) {
+	if len(priv) != t.PrivateKeyLen() || t.PrivateKeyLen() == 0 {
+		return EncryptionKeyPair{}, fmt.Errorf("invalid %s private key length %d", t, len(priv))
+	}
+	if t == CryptoTypeElGamal {
+		x := new(big.Int).SetBytes(priv)
+		if x.Sign() == 0 || x.Cmp(elgamalP) >= 0 {
+			return EncryptionKeyPair{}, errors.New("invalid ElGamal private key")
+		}
+		y := new(big.Int).Exp(elgamalG, x, elgamalP)
+		return EncryptionKeyPair{Type: t, PublicKey: y.FillBytes(make([]byte, t.PublicKeyLen())), PrivateKey: append([]byte{}, priv...)}, nil
+	}
+	curve, ok := ecdhCurve(t)
+	if !ok {
+		return EncryptionKeyPair{}, fmt.Errorf("%w: %s", ErrUnsupportedCryptoType, t)
+	}
+	key, err := curve.NewPrivateKey(priv)
+	if err != nil {
+		return EncryptionKeyPair{}, fmt.Errorf("invalid %s private key: %w", t, err)
+	}
+	return EncryptionKeyPair{Type: t, PublicKey: ecdhPublicBytes(t, key.PublicKey()), PrivateKey: key.Bytes()}, nil
+}
+
+// Bytes returns the public key as it appears in the encryption key section of
+// a LeaseSet2: type (2 bytes), length (2 bytes), key.
+func (k EncryptionKeyPair) Bytes() []byte {
+	b := make([]byte, 4, 4+len(k.PublicKey))
+	binary.BigEndian.PutUint16(b[0:2], uint16(k.Type))
+	binary.BigEndian.PutUint16(b[2:4], uint16(len(k.PublicKey)))
+	return append(b, k.PublicKey...)
+}
+
+func mlkemScheme(t CryptoType) (kem.Scheme, bool) {
+	switch t {
+	case CryptoTypeMLKEM512X25519, CryptoTypeMLKEM512:
+		return mlkem512.Scheme(), true
+	case CryptoTypeMLKEM768X25519, CryptoTypeMLKEM768:
+		return mlkem768.Scheme(), true
+	case CryptoTypeMLKEM1024X25519, CryptoTypeMLKEM1024:
+		return mlkem1024.Scheme(), true
+	}
+	return nil, false
+}
+
+// MLKEMCiphertextLen returns the length of an ML-KEM ciphertext for a hybrid
+// or ML-KEM type, or 0 for other types.
+func (t CryptoType) MLKEMCiphertextLen() int {
+	if scheme, ok := mlkemScheme(t); ok {
+		return scheme.CiphertextSize()
+	}
+	return 0
+}
+
+// GenerateMLKEMKey creates the ML-KEM key pair of a hybrid type (or of a
+// plain ML-KEM type), as used in handshakes. The keys have the lengths of
+// crypto types 8 to 10.
+func GenerateMLKEMKey(t CryptoType) (pub, priv []byte, err error) {
+	scheme, ok := mlkemScheme(t)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s is not an ML-KEM type", ErrUnsupportedCryptoType, t)
+	}
+	pk, sk, err := scheme.GenerateKeyPair()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error generating key: %w", err)
+	}
+	if pub, err = pk.MarshalBinary(); err != nil {
+		return nil, nil, err
+	}
+	if priv, err = sk.MarshalBinary(); err != nil {
+		return nil, nil, err
+	}
+	return pub, priv, nil
+}
+
+// MLKEMEncapsulate creates a shared secret for an ML-KEM public key and the
+// ciphertext which transports it.
+func MLKEMEncapsulate(t CryptoType, pub []byte) (ciphertext, shared []byte, err error) {
+	scheme, ok := mlkemScheme(t)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s is not an ML-KEM type", ErrUnsupportedCryptoType, t)
+	}
+	pk, err := scheme.UnmarshalBinaryPublicKey(pub)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid %s public key: %w", t, err)
+	}
+	return scheme.Encapsulate(pk)
+}
+
+// MLKEMDecapsulate recovers the shared secret from an ML-KEM ciphertext.
+func MLKEMDecapsulate(t CryptoType, priv, ciphertext []byte) ([]byte, error) {
+	scheme, ok := mlkemScheme(t)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is not an ML-KEM type", ErrUnsupportedCryptoType, t)
+	}
+	sk, err := scheme.UnmarshalBinaryPrivateKey(priv)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s private key: %w", t, err)
+	}
+	if len(ciphertext) != scheme.CiphertextSize() {
+		return nil, fmt.Errorf("invalid %s ciphertext length %d", t, len(ciphertext))
+	}
+	return scheme.Decapsulate(sk, ciphertext)
+}
+
+// GenerateDestination creates a new destination locally, without a SAM
+// bridge, with the given signature and encryption types. The unused space in
+// the destination is filled with a repeated random pattern as recommended by
+// the spec. Only the types allowed in destinations may be used, the
+// post-quantum types are limited to LeaseSets.
+func GenerateDestination(sigType SigType, cryptoType CryptoType) (I2PKeys, error) {
+	log.WithField("sigType", sigType).WithField("cryptoType", cryptoType).Debug("Generating destination")
+	if !cryptoType.identityType() {
+		return I2PKeys{}, fmt.Errorf("%w: %s cannot be used in destinations", ErrUnsupportedCryptoType, cryptoType)
+	}
+	enc, err := GenerateEncryptionKey(cryptoType)
+	if err != nil {
+		return I2PKeys{}, err
+	}
+	sigPub, sigPriv, err := GenerateSigningKey(sigType)
+	if err != nil {
+		return I2PKeys{}, err
+	}
+	padding := make([]byte, 32)
+	if _, err := rand.Read(padding); err != nil {
+		return I2PKeys{}, fmt.Errorf("error generating padding: %w", err)
+	}
+	raw, err := newKeysAndCert(cryptoType, enc.PublicKey, sigType, sigPub, padding)
+	if err != nil {
+		return I2PKeys{}, err
+	}
+	dest, err := readKeysAndCert(raw)
+	if err != nil {
+		return I2PKeys{}, err
+	}
+	k := privateKeyFile{dest: dest, privateKey: enc.PrivateKey, signingPrivateKey: sigPriv}.keys()
+	log.WithField("addr", k.Address.Base32()).Debug("Generated destination")
+	return k, nil
+}
+
+// EncryptionKey returns the encryption key pair held by the keys.
+func (k I2PKeys) EncryptionKey() (EncryptionKeyPair, error) {
+	p, err := k.privateKeyFile()
+	if err != nil {
+		return EncryptionKeyPair{}, err
+	}
+	return EncryptionKeyPair{
+		Type:       p.dest.cryptoType,
+		PublicKey:  append([]byte{}, p.dest.cryptoKey...),
+		PrivateKey: append([]byte{}, p.privateKey...),
+	}, nil
+}
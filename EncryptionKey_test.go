@@ -0,0 +1,87 @@
+package i2pkeys
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_EncryptionKey(t *testing.T) {
+	types := []CryptoType{CryptoTypeElGamal, CryptoTypeP256, CryptoTypeP384, CryptoTypeP521, CryptoTypeX25519,
+		CryptoTypeMLKEM512X25519, CryptoTypeMLKEM768X25519, CryptoTypeMLKEM1024X25519}
+	for _, cryptoType := range types {
+		t.Run(cryptoType.String(), func(t *testing.T) {
+			k, err := GenerateEncryptionKey(cryptoType)
+			if err != nil {
+				t.Fatalf("GenerateEncryptionKey failed: %v", err)
+			}
+			if len(k.PublicKey) != cryptoType.PublicKeyLen() || len(k.PrivateKey) != cryptoType.PrivateKeyLen() {
+				t.Fatalf("Wrong key lengths %d/%d", len(k.PublicKey), len(k.PrivateKey))
+			}
+			restored, err := NewEncryptionKeyPair(cryptoType, k.PrivateKey)
+			if err != nil {
+				t.Fatalf("NewEncryptionKeyPair failed: %v", err)
+			}
+			if !bytes.Equal(restored.PublicKey, k.PublicKey) {
+				t.Error("Restored public key does not match")
+			}
+			if b := k.Bytes(); len(b) != 4+cryptoType.PublicKeyLen() || b[1] != byte(cryptoType) {
+				t.Errorf("Unexpected LeaseSet encoding %x", b[:4])
+			}
+		})
+	}
+
+	t.Run("ML-KEM", func(t *testing.T) {
+		sizes := map[CryptoType][3]int{
+			CryptoTypeMLKEM512X25519:  {800, 1632, 768},
+			CryptoTypeMLKEM768X25519:  {1184, 2400, 1088},
+			CryptoTypeMLKEM1024X25519: {1568, 3168, 1568},
+		}
+		for cryptoType, size := range sizes {
+			pub, priv, err := GenerateMLKEMKey(cryptoType)
+			if err != nil {
+				t.Fatalf("GenerateMLKEMKey(%s) failed: %v", cryptoType, err)
+			}
+			if len(pub) != size[0] || len(priv) != size[1] || cryptoType.MLKEMCiphertextLen() != size[2] {
+				t.Errorf("%s: wrong sizes %d/%d/%d", cryptoType, len(pub), len(priv), cryptoType.MLKEMCiphertextLen())
+			}
+			ct, shared, err := MLKEMEncapsulate(cryptoType, pub)
+			if err != nil {
+				t.Fatalf("MLKEMEncapsulate failed: %v", err)
+			}
+			got, err := MLKEMDecapsulate(cryptoType, priv, ct)
+			if err != nil {
+				t.Fatalf("MLKEMDecapsulate failed: %v", err)
+			}
+			if !bytes.Equal(got, shared) {
+				t.Errorf("%s: shared secrets differ", cryptoType)
+			}
+		}
+		if _, _, err := GenerateMLKEMKey(CryptoTypeX25519); err == nil {
+			t.Error("GenerateMLKEMKey should have failed for X25519")
+		}
+	})
+
+	t.Run("Destinations", func(t *testing.T) {
+		for _, cryptoType := range []CryptoType{CryptoTypeElGamal, CryptoTypeX25519} {
+			keys, err := GenerateDestination(SigTypeEd25519, cryptoType)
+			if err != nil {
+				t.Fatalf("GenerateDestination failed: %v", err)
+			}
+			got, err := keys.Addr().CryptoType()
+			if err != nil || got != cryptoType {
+				t.Errorf("Destination has encryption type %s, want %s (%v)", got, cryptoType, err)
+			}
+			enc, err := keys.EncryptionKey()
+			if err != nil {
+				t.Fatalf("EncryptionKey failed: %v", err)
+			}
+			restored, _ := NewEncryptionKeyPair(cryptoType, enc.PrivateKey)
+			if !bytes.Equal(restored.PublicKey, enc.PublicKey) {
+				t.Error("Destination encryption keys do not match")
+			}
+		}
+		if _, err := GenerateDestination(SigTypeEd25519, CryptoTypeMLKEM768X25519); err == nil {
+			t.Error("GenerateDestination should have failed for a LeaseSet-only type")
+		}
+	})
+}
@@ -0,0 +1,126 @@
+package i2pkeys
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+const (
+	randomartWidth  = 17
+	randomartHeight = 9
+	randomartChars  = " .o+=*BOX@%&#/^SE"
+
+	// FingerprintWords is the number of words of a word fingerprint, 88 bits
+	// of the hash.
+	FingerprintWords = 8
+
+	safetyNumberIterations = 5200
+	safetyNumberGroups     = 3 // groups of 5 digits per destination
+)
+
+// Randomart draws the hash as an OpenSSH style "drunken bishop" image, which
+// is easier to compare at a glance than the address.
+func (h I2PDestHash) Randomart() string {
+	var field [randomartWidth][randomartHeight]int
+	last := len(randomartChars) - 1
+	x, y := randomartWidth/2, randomartHeight/2
+	for _, b := range h {
+		for i := 0; i < 4; i++ {
+			if b&1 != 0 {
+				x++
+			} else {
+				x--
+			}
+			if b&2 != 0 {
+				y++
+			} else {
+				y--
+			}
+			x = max(0, min(x, randomartWidth-1))
+			y = max(0, min(y, randomartHeight-1))
+			// Counts stop at '^', like OpenSSH, so that only the start
+			// and end are drawn as S and E.
+			if field[x][y] < last-2 {
+				field[x][y]++
+			}
+			b >>= 2
+		}
+	}
+	field[randomartWidth/2][randomartHeight/2] = last - 1
+	field[x][y] = last
+
+	var sb strings.Builder
+	sb.WriteString(randomartBorder("[I2P]"))
+	for row := 0; row < randomartHeight; row++ {
+		sb.WriteByte('|')
+		for col := 0; col < randomartWidth; col++ {
+			sb.WriteByte(randomartChars[field[col][row]])
+		}
+		sb.WriteString("|\n")
+	}
+	sb.WriteString(randomartBorder("[SHA256]"))
+	return sb.String()
+}
+
+func randomartBorder(title string) string {
+	pad := randomartWidth - len(title)
+	return "+" + strings.Repeat("-", pad/2) + title + strings.Repeat("-", pad-pad/2) + "+\n"
+}
+
+// WordFingerprint returns the first 88 bits of the hash as FingerprintWords
+// words of the BIP39 English word list, to be read aloud.
+func (h I2PDestHash) WordFingerprint() []string {
+	words := make([]string, FingerprintWords)
+	var acc uint64
+	var bits uint
+	n := 0
+	for _, b := range h {
+		acc = acc<<8 | uint64(b)
+		bits += 8
+		for bits >= 11 && n < FingerprintWords {
+			bits -= 11
+			words[n] = bip39Words[acc>>bits&0x7ff]
+			n++
+		}
+		if n == FingerprintWords {
+			break
+		}
+	}
+	return words
+}
+
+// safetyNumberHalf computes the digits contributed by one destination. The
+// hash is iterated so that finding a destination with the same digits is
+// expensive.
+func safetyNumberHalf(h I2PDestHash) string {
+	digest := append([]byte("i2pkeys safety number v1"), h[:]...)
+	for i := 0; i < safetyNumberIterations; i++ {
+		d := sha512.Sum512(append(digest, h[:]...))
+		digest = d[:]
+	}
+	var sb strings.Builder
+	for i := 0; i < safetyNumberGroups; i++ {
+		chunk := binary.BigEndian.Uint64(append([]byte{0, 0, 0}, digest[5*i:5*i+5]...))
+		fmt.Fprintf(&sb, "%05d", chunk%100000)
+	}
+	return sb.String()
+}
+
+// SafetyNumber returns a numeric code for the pair of destinations a and b,
+// in groups of five digits. Both sides compute the same code regardless of
+// the order of the arguments, and compare it over another channel, like
+// Signal's safety numbers.
+func SafetyNumber(a, b I2PDestHash) string {
+	if bytes.Compare(a[:], b[:]) > 0 {
+		a, b = b, a
+	}
+	digits := safetyNumberHalf(a) + safetyNumberHalf(b)
+	groups := make([]string, 0, len(digits)/5)
+	for i := 0; i < len(digits); i += 5 {
+		groups = append(groups, digits[i:i+5])
+	}
+	return strings.Join(groups, " ")
+}
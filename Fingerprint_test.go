@@ -0,0 +1,78 @@
+package i2pkeys
+
+import (
+	"crypto/sha256"
+	"strings"
+	"testing"
+)
+
+func Test_Fingerprint(t *testing.T) {
+	a := I2PAddr(validI2PAddrB64).DestHash()
+	var b I2PDestHash
+	for i := range b {
+		b[i] = byte(i)
+	}
+
+	t.Run("Randomart", func(t *testing.T) {
+		art := a.Randomart()
+		lines := strings.Split(strings.TrimSuffix(art, "\n"), "\n")
+		if len(lines) != randomartHeight+2 {
+			t.Fatalf("Randomart has %d lines:\n%s", len(lines), art)
+		}
+		for _, l := range lines {
+			if len(l) != randomartWidth+2 {
+				t.Errorf("Randomart line %q has length %d", l, len(l))
+			}
+		}
+		body := strings.Join(lines[1:len(lines)-1], "")
+		if strings.Count(body, "S") != 1 || strings.Count(body, "E") != 1 {
+			t.Errorf("Randomart lacks start or end marker:\n%s", art)
+		}
+		if art != a.Randomart() || art == b.Randomart() {
+			t.Error("Randomart is not deterministic or not distinct")
+		}
+	})
+
+	t.Run("Randomart markers are unique", func(t *testing.T) {
+		for i := 0; i < 2000; i++ {
+			h := I2PDestHash(sha256.Sum256([]byte{byte(i), byte(i >> 8)}))
+			art := h.Randomart()
+			lines := strings.Split(strings.TrimSuffix(art, "\n"), "\n")
+			body := strings.Join(lines[1:len(lines)-1], "")
+			if strings.Count(body, "S") > 1 || strings.Count(body, "E") != 1 {
+				t.Fatalf("Randomart of %s repeats a start or end marker:\n%s", h, art)
+			}
+		}
+	})
+
+	t.Run("Words", func(t *testing.T) {
+		words := b.WordFingerprint()
+		// 0x00010203... in 11 bit groups: 0, 64, 1030, ...
+		if len(words) != FingerprintWords || words[0] != bip39Words[0] || words[1] != bip39Words[64] || words[2] != bip39Words[1030] {
+			t.Errorf("Unexpected word fingerprint %v", words)
+		}
+		if strings.Join(a.WordFingerprint(), " ") == strings.Join(words, " ") {
+			t.Error("Different hashes gave the same words")
+		}
+	})
+
+	t.Run("Safety number", func(t *testing.T) {
+		ab, ba := SafetyNumber(a, b), SafetyNumber(b, a)
+		if ab != ba {
+			t.Errorf("Safety number depends on the order: %s vs %s", ab, ba)
+		}
+		groups := strings.Fields(ab)
+		if len(groups) != 2*safetyNumberGroups {
+			t.Fatalf("Unexpected safety number %q", ab)
+		}
+		for _, g := range groups {
+			if len(g) != 5 || strings.Trim(g, "0123456789") != "" {
+				t.Errorf("Invalid group %q in %q", g, ab)
+			}
+		}
+		var c I2PDestHash
+		if SafetyNumber(a, c) == ab {
+			t.Error("Different pairs gave the same safety number")
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package i2pkeys
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// writeFormatted writes s honouring the width, precision and '-' flag of f.
+func writeFormatted(f fmt.State, s string) {
+	var directive strings.Builder
+	directive.WriteByte('%')
+	if f.Flag('-') {
+		directive.WriteByte('-')
+	}
+	if w, ok := f.Width(); ok {
+		directive.WriteString(strconv.Itoa(w))
+	}
+	if p, ok := f.Precision(); ok {
+		directive.WriteByte('.')
+		directive.WriteString(strconv.Itoa(p))
+	}
+	directive.WriteByte('s')
+	fmt.Fprintf(f, directive.String(), s)
+}
+
+// HexHash returns the destination hash in hexadecimal.
+func (h I2PDestHash) HexHash() string {
+	return hex.EncodeToString(h[:])
+}
+
+// Base64 returns the destination hash in I2P's base64 alphabet, the form
+// the router console and netDb use.
+func (h I2PDestHash) Base64() string {
+	return i2pB64enc.EncodeToString(h[:])
+}
+
+// Format implements fmt.Formatter, independently of StringIsBase64:
+//
+//	%s   the .b32.i2p address
+//	%+s  the base64 destination
+//	%x   the destination hash in hexadecimal (%X in upper case)
+//	%q   the .b32.i2p address, quoted (%+q the base64 destination)
+//	%v   String(), which still follows the deprecated StringIsBase64
+//	%+v  the base64 destination
+//	%#v  Go syntax
+func (a I2PAddr) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 's', 'q':
+		s := a.Base32()
+		if f.Flag('+') {
+			s = a.Base64()
+		}
+		if verb == 'q' {
+			s = strconv.Quote(s)
+		}
+		writeFormatted(f, s)
+	case 'x':
+		writeFormatted(f, a.DestHash().HexHash())
+	case 'X':
+		writeFormatted(f, strings.ToUpper(a.DestHash().HexHash()))
+	case 'v':
+		switch {
+		case f.Flag('#'):
+			fmt.Fprintf(f, "i2pkeys.I2PAddr(%q)", string(a))
+		case f.Flag('+'):
+			writeFormatted(f, a.Base64())
+		default:
+			writeFormatted(f, a.String())
+		}
+	default:
+		fmt.Fprintf(f, "%%!%c(i2pkeys.I2PAddr=%s)", verb, a.Base32())
+	}
+}
+
+// Format implements fmt.Formatter:
+//
+//	%s, %v  the .b32.i2p address
+//	%+s     the hash in base64
+//	%x      the hash in hexadecimal (%X in upper case)
+//	%q      the .b32.i2p address, quoted
+//	%#v     Go syntax
+func (h I2PDestHash) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 's', 'v', 'q':
+		if verb == 'v' && f.Flag('#') {
+			fmt.Fprintf(f, "i2pkeys.I2PDestHash(%#v)", [32]byte(h))
+			return
+		}
+		s := h.String()
+		if f.Flag('+') {
+			s = h.Base64()
+		}
+		if verb == 'q' {
+			s = strconv.Quote(s)
+		}
+		writeFormatted(f, s)
+	case 'x':
+		writeFormatted(f, h.HexHash())
+	case 'X':
+		writeFormatted(f, strings.ToUpper(h.HexHash()))
+	default:
+		fmt.Fprintf(f, "%%!%c(i2pkeys.I2PDestHash=%s)", verb, h.String())
+	}
+}
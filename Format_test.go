@@ -0,0 +1,54 @@
+package i2pkeys
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func Test_Format(t *testing.T) {
+	addr := I2PAddr(validI2PAddrB64)
+	hash := addr.DestHash()
+
+	tests := []struct {
+		format string
+		value  any
+		want   string
+	}{
+		{"%s", addr, addr.Base32()},
+		{"%+s", addr, addr.Base64()},
+		{"%x", addr, hash.HexHash()},
+		{"%X", addr, strings.ToUpper(hash.HexHash())},
+		{"%q", addr, `"` + addr.Base32() + `"`},
+		{"%v", addr, addr.Base32()},
+		{"%+v", addr, addr.Base64()},
+		{"%70s|", addr, "          " + addr.Base32() + "|"},
+		{"%-62s|", addr, addr.Base32() + "  |"},
+		{"%s", hash, addr.Base32()},
+		{"%v", hash, addr.Base32()},
+		{"%+s", hash, hash.Base64()},
+		{"%x", hash, hash.HexHash()},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf(tt.format, tt.value); got != tt.want {
+			t.Errorf("Sprintf(%q, %T) = %q, want %q", tt.format, tt.value, got, tt.want)
+		}
+	}
+
+	t.Run("Go syntax", func(t *testing.T) {
+		if got := fmt.Sprintf("%#v", addr); !strings.HasPrefix(got, `i2pkeys.I2PAddr("`) {
+			t.Errorf("Unexpected %%#v: %s", got)
+		}
+	})
+
+	t.Run("Deprecated default", func(t *testing.T) {
+		StringIsBase64 = true
+		defer func() { StringIsBase64 = false }()
+		if got := fmt.Sprintf("%v", addr); got != addr.Base64() {
+			t.Errorf("%%v should follow StringIsBase64, got %s", got)
+		}
+		if got := fmt.Sprintf("%s", addr); got != addr.Base32() {
+			t.Errorf("%%s should not follow StringIsBase64, got %s", got)
+		}
+	})
+}
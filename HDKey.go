@@ -0,0 +1,184 @@
+package i2pkeys
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// hdMasterSalt is the HMAC key used to turn a master seed into the root of a
+// derivation tree. Changing it changes every derived address.
+const hdMasterSalt = "i2pkeys HD seed v1"
+
+// HardenedOffset is added to every child index. All derivation is hardened,
+// so a leaked child key, or the destination derived from it, reveals nothing
+// about its parent or siblings.
+const HardenedOffset uint32 = 0x80000000
+
+// HDKey is a node in a tree of destinations derived from a single master
+// seed. Every node can be turned into a destination with Keys(), and into
+// further children with Child, ChildLabel or DerivePath. Backing up the
+// master seed is enough to recreate the whole tree.
+type HDKey struct {
+	key       [32]byte
+	chainCode [32]byte
+	depth     int
+}
+
+// NewHDKey creates the root of a derivation tree from a master seed of 16 to
+// 64 bytes, for instance one created by NewSeed or MnemonicToSeed.
+func NewHDKey(seed []byte) (*HDKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, fmt.Errorf("invalid master seed length %d", len(seed))
+	}
+	mac := hmac.New(sha512.New, []byte(hdMasterSalt))
+	mac.Write(seed)
+	return newHDKey(mac.Sum(nil), 0), nil
+}
+
+func newHDKey(i []byte, depth int) *HDKey {
+	k := &HDKey{depth: depth}
+	copy(k.key[:], i[:32])
+	copy(k.chainCode[:], i[32:])
+	wipe(i)
+	return k
+}
+
+func (k *HDKey) derive(tag byte, data []byte) *HDKey {
+	mac := hmac.New(sha512.New, k.chainCode[:])
+	mac.Write([]byte{tag})
+	mac.Write(k.key[:])
+	mac.Write(data)
+	return newHDKey(mac.Sum(nil), k.depth+1)
+}
+
+// Child derives the hardened child with the given index. Indexes below
+// HardenedOffset are hardened automatically.
+func (k *HDKey) Child(index uint32) *HDKey {
+	var data [4]byte
+	binary.BigEndian.PutUint32(data[:], index|HardenedOffset)
+	return k.derive(0, data[:])
+}
+
+// ChildLabel derives the hardened child named by an arbitrary label, such as
+// a customer or service name. Labels are case sensitive.
+func (k *HDKey) ChildLabel(label string) *HDKey {
+	return k.derive(1, []byte(label))
+}
+
+// DerivePath derives a descendant from a path such as "m/0'/customers/acme".
+// Segments made of digits followed by exactly one ' or h are child indexes,
+// any other segment, including plain numbers such as "2024", is a label as
+// for ChildLabel. A leading "m" segment is optional; "m" alone returns a
+// copy of k.
+func (k *HDKey) DerivePath(path string) (*HDKey, error) {
+	segments := strings.Split(path, "/")
+	if len(segments) > 0 && segments[0] == "m" {
+		segments = segments[1:]
+	}
+	root := *k
+	node := &root
+	for i, segment := range segments {
+		if segment == "" {
+			node.Wipe()
+			return nil, fmt.Errorf("empty segment %d in path %q", i+1, path)
+		}
+		index, isIndex, err := parseHDIndex(segment)
+		if err != nil {
+			node.Wipe()
+			return nil, fmt.Errorf("segment %d in path %q: %w", i+1, path, err)
+		}
+		var next *HDKey
+		if isIndex {
+			next = node.Child(index)
+		} else {
+			next = node.ChildLabel(segment)
+		}
+		node.Wipe()
+		node = next
+	}
+	return node, nil
+}
+
+// parseHDIndex parses a hardened index segment such as "0'" or "5h". It
+// reports false for labels, and fails for segments which look like a
+// malformed index, such as "5'h" or an index out of range.
+func parseHDIndex(segment string) (index uint32, ok bool, err error) {
+	digits := strings.TrimRight(segment, "'h")
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return 0, false, nil
+	}
+	if markers := len(segment) - len(digits); markers == 0 {
+		return 0, false, nil
+	} else if markers > 1 {
+		return 0, false, fmt.Errorf("malformed index %q", segment)
+	}
+	n, err := strconv.ParseUint(digits, 10, 32)
+	if err != nil || n >= uint64(HardenedOffset) {
+		return 0, false, fmt.Errorf("index %q out of range", segment)
+	}
+	return uint32(n), true, nil
+}
+
+// Depth returns the number of derivation steps from the master seed.
+func (k *HDKey) Depth() int {
+	return k.depth
+}
+
+// Keys returns the destination belonging to this node.
+func (k *HDKey) Keys() (I2PKeys, error) {
+	return NewKeysFromSeed(k.key[:])
+}
+
+// Addr returns the public destination belonging to this node.
+func (k *HDKey) Addr() (I2PAddr, error) {
+	return NewAddrFromSeed(k.key[:])
+}
+
+// LabelAddresses maps each label to the .b32.i2p address of the child
+// ChildLabel derives for it. Only public destinations are returned, private
+// key material is wiped as soon as each address has been computed.
+func (k *HDKey) LabelAddresses(labels ...string) (map[string]I2PDestHash, error) {
+	m := make(map[string]I2PDestHash, len(labels))
+	for _, label := range labels {
+		child := k.ChildLabel(label)
+		addr, err := child.Addr()
+		child.Wipe()
+		if err != nil {
+			return nil, fmt.Errorf("error deriving %q: %w", label, err)
+		}
+		m[label] = addr.DestHash()
+	}
+	return m, nil
+}
+
+// PathAddresses is like LabelAddresses, but derives each entry with
+// DerivePath.
+func (k *HDKey) PathAddresses(paths ...string) (map[string]I2PDestHash, error) {
+	m := make(map[string]I2PDestHash, len(paths))
+	for _, path := range paths {
+		child, err := k.DerivePath(path)
+		if err != nil {
+			return nil, err
+		}
+		addr, err := child.Addr()
+		if child != k {
+			child.Wipe()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error deriving %q: %w", path, err)
+		}
+		m[path] = addr.DestHash()
+	}
+	return m, nil
+}
+
+// Wipe overwrites the secret material of the node. The node must not be used
+// afterwards.
+func (k *HDKey) Wipe() {
+	wipe(k.key[:])
+	wipe(k.chainCode[:])
+}
@@ -0,0 +1,138 @@
+package i2pkeys
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_HDKey(t *testing.T) {
+	master, err := NewHDKey(bytes.Repeat([]byte{0x01}, SeedSize))
+	if err != nil {
+		t.Fatalf("NewHDKey failed: %v", err)
+	}
+
+	t.Run("Deterministic", func(t *testing.T) {
+		again, _ := NewHDKey(bytes.Repeat([]byte{0x01}, SeedSize))
+		a, err := master.ChildLabel("acme").Keys()
+		if err != nil {
+			t.Fatalf("Keys failed: %v", err)
+		}
+		b, err := again.ChildLabel("acme").Keys()
+		if err != nil {
+			t.Fatalf("Keys failed: %v", err)
+		}
+		if a != b {
+			t.Error("Same master seed and label produced different keys")
+		}
+	})
+
+	t.Run("Siblings differ", func(t *testing.T) {
+		a, _ := master.Child(0).Addr()
+		b, _ := master.Child(1).Addr()
+		c, _ := master.ChildLabel("0").Addr()
+		if a == b || a == c {
+			t.Error("Sibling nodes produced the same address")
+		}
+		m, _ := master.Addr()
+		if m == a {
+			t.Error("Child produced the master's address")
+		}
+	})
+
+	t.Run("Path", func(t *testing.T) {
+		node, err := master.DerivePath("m/0'/customers/acme")
+		if err != nil {
+			t.Fatalf("DerivePath failed: %v", err)
+		}
+		want, _ := master.Child(0).ChildLabel("customers").ChildLabel("acme").Addr()
+		got, _ := node.Addr()
+		if got != want {
+			t.Error("DerivePath does not match explicit derivation")
+		}
+		if node.Depth() != 3 {
+			t.Errorf("Wrong depth %d", node.Depth())
+		}
+		if _, err := master.DerivePath("m//acme"); err == nil {
+			t.Error("DerivePath should have failed for empty segment")
+		}
+		hardened, _ := master.DerivePath("m/5h")
+		if got, _ := hardened.Addr(); got != mustAddr(t, master.Child(5)) {
+			t.Error("DerivePath with h marker does not match Child")
+		}
+	})
+
+	t.Run("Master path is a copy", func(t *testing.T) {
+		want := mustAddr(t, master)
+		root, err := master.DerivePath("m")
+		if err != nil {
+			t.Fatalf("DerivePath failed: %v", err)
+		}
+		if root == master || mustAddr(t, root) != want {
+			t.Fatal("DerivePath(\"m\") should return a copy of the master")
+		}
+		root.Wipe()
+		if mustAddr(t, master) != want {
+			t.Error("Wiping the result of DerivePath changed the master")
+		}
+	})
+
+	t.Run("Malformed index", func(t *testing.T) {
+		for _, path := range []string{"m/5''", "m/5h'h", "m/5'h", "m/2147483648'", "m/99999999999h"} {
+			if _, err := master.DerivePath(path); err == nil {
+				t.Errorf("DerivePath(%q) should have failed", path)
+			}
+		}
+	})
+
+	t.Run("Numeric labels", func(t *testing.T) {
+		node, err := master.DerivePath("2024")
+		if err != nil {
+			t.Fatalf("DerivePath failed: %v", err)
+		}
+		if mustAddr(t, node) != mustAddr(t, master.ChildLabel("2024")) {
+			t.Error("DerivePath of a plain number does not match ChildLabel")
+		}
+		if mustAddr(t, node) == mustAddr(t, master.Child(2024)) {
+			t.Error("DerivePath of a plain number matches the hardened index")
+		}
+		paths, _ := master.PathAddresses("2024", "growth")
+		labels, _ := master.LabelAddresses("2024", "growth")
+		for _, label := range []string{"2024", "growth"} {
+			if paths[label] != labels[label] {
+				t.Errorf("PathAddresses and LabelAddresses differ for %q", label)
+			}
+		}
+	})
+
+	t.Run("Label addresses", func(t *testing.T) {
+		labels := []string{"acme", "globex", "initech"}
+		addrs, err := master.LabelAddresses(labels...)
+		if err != nil {
+			t.Fatalf("LabelAddresses failed: %v", err)
+		}
+		for _, label := range labels {
+			k, err := master.ChildLabel(label).Keys()
+			if err != nil {
+				t.Fatalf("Keys failed: %v", err)
+			}
+			if addrs[label].String() != k.Addr().Base32() {
+				t.Errorf("Address for %s does not match derived keys", label)
+			}
+		}
+	})
+
+	t.Run("Invalid seed", func(t *testing.T) {
+		if _, err := NewHDKey([]byte{1, 2, 3}); err == nil {
+			t.Error("NewHDKey should have failed for short seed")
+		}
+	})
+}
+
+func mustAddr(t *testing.T, k *HDKey) I2PAddr {
+	t.Helper()
+	addr, err := k.Addr()
+	if err != nil {
+		t.Fatalf("Addr failed: %v", err)
+	}
+	return addr
+}
@@ -0,0 +1,249 @@
+package i2pkeys
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Limits of I2P hostnames, as enforced by the router's address book.
+const (
+	hostnameMaxLen        = 67
+	hostnameMaxLabelLen   = 63
+	hostnameMaxSubdomains = 3 // labels in front of the registered name
+)
+
+var (
+	ErrInvalidHostname    = errors.New("invalid I2P hostname")
+	ErrReservedHostname   = errors.New("reserved I2P hostname")
+	ErrConfusableHostname = errors.New("confusable I2P hostname")
+)
+
+// hostnameReserved are names, with their subdomains, which the router
+// handles itself.
+var hostnameReserved = []string{"b32", "console", "localhost", "proxy", "router"}
+
+// hostnameConfusables maps characters to the ASCII character they are
+// commonly mistaken for, after the Unicode confusables list (UTS #39).
+var hostnameConfusables = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'в': 'b', 'с': 'c', 'ԁ': 'd', 'е': 'e', 'ё': 'e', 'һ': 'h', 'і': 'i',
+	'ї': 'i', 'ј': 'j', 'к': 'k', 'ӏ': 'l', 'м': 'm', 'н': 'h', 'о': 'o', 'р': 'p',
+	'ԛ': 'q', 'ѕ': 's', 'т': 't', 'у': 'y', 'ԝ': 'w', 'х': 'x', 'ү': 'y', 'ь': 'b',
+	// Greek
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o',
+	'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x', 'ω': 'w',
+	// Latin lookalikes
+	'ı': 'i', 'ɑ': 'a', 'ɡ': 'g', 'ǀ': 'l', 'ɩ': 'i', 'ɪ': 'i', 'ʏ': 'y',
+	// digits
+	'0': 'o', '1': 'l',
+}
+
+// hostnameScriptSets are the combinations of scripts allowed in one label,
+// the "highly restrictive" profile of UTS #39. Labels in a single script are
+// always allowed.
+var hostnameScriptSets = [][]string{
+	{"Latin", "Han", "Hiragana", "Katakana"},
+	{"Latin", "Han", "Bopomofo"},
+	{"Latin", "Han", "Hangul"},
+}
+
+// runeScript returns the name of the script of r, or "" for characters
+// shared between scripts such as digits and combining marks.
+func runeScript(r rune) string {
+	if r < utf8.RuneSelf {
+		if unicode.IsLetter(r) {
+			return "Latin"
+		}
+		return ""
+	}
+	for name, table := range unicode.Scripts {
+		if name != "Common" && name != "Inherited" && unicode.Is(table, r) {
+			return name
+		}
+	}
+	return ""
+}
+
+// ValidateHostname checks name against the I2P hostname rules and returns
+// it in its canonical form, lower case with internationalized labels in
+// punycode. The name must end in .i2p, be at most 67 characters long in
+// ASCII form and have at most three levels of subdomains; labels consist of
+// letters, digits and hyphens, and neither start nor end with a hyphen.
+// .b32.i2p addresses and the router's own names are reserved.
+//
+// Unicode labels are checked for homographs: mixing scripts, such as Latin
+// and Cyrillic, or spelling an ASCII name entirely with lookalikes fails
+// with ErrConfusableHostname. Importers should also compare the
+// HostnameSkeleton of new names with those already known.
+func ValidateHostname(name string) (string, error) {
+	log.WithField("hostname", name).Debug("Validating hostname")
+	lower := strings.ToLower(name)
+	if !strings.HasSuffix(lower, ".i2p") {
+		return "", fmt.Errorf("%w: %q does not end in .i2p", ErrInvalidHostname, name)
+	}
+	labels := strings.Split(strings.TrimSuffix(lower, ".i2p"), ".")
+	if len(labels) > hostnameMaxSubdomains+1 {
+		return "", fmt.Errorf("%w: %q has more than %d levels of subdomains", ErrInvalidHostname, name, hostnameMaxSubdomains)
+	}
+	ascii := make([]string, len(labels))
+	for i, label := range labels {
+		a, err := validateHostnameLabel(label)
+		if err != nil {
+			return "", fmt.Errorf("%w: %q: %v", ErrInvalidHostname, name, err)
+		}
+		ascii[i] = a
+	}
+	host := strings.Join(ascii, ".") + ".i2p"
+	if len(host) > hostnameMaxLen {
+		return "", fmt.Errorf("%w: %q is longer than %d characters", ErrInvalidHostname, host, hostnameMaxLen)
+	}
+	for _, r := range hostnameReserved {
+		if ascii[len(ascii)-1] == r {
+			return "", fmt.Errorf("%w: %q", ErrReservedHostname, host)
+		}
+	}
+	for _, label := range labels {
+		if err := checkHomograph(label); err != nil {
+			return "", fmt.Errorf("%w: %q: %v", ErrConfusableHostname, name, err)
+		}
+	}
+	return host, nil
+}
+
+// validateHostnameLabel checks a single label and returns its ASCII form.
+func validateHostnameLabel(label string) (string, error) {
+	if label == "" {
+		return "", errors.New("empty label")
+	}
+	ascii := label
+	if strings.HasPrefix(label, "xn--") {
+		u, err := punycodeDecode(label[4:])
+		if err != nil || "xn--"+punycodeEncode(u) != label {
+			return "", fmt.Errorf("label %q is not valid punycode", label)
+		}
+		label = u
+	}
+	if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+		return "", fmt.Errorf("label %q starts or ends with a hyphen", label)
+	}
+	if strings.Contains(label, "--") {
+		return "", fmt.Errorf("label %q contains a double hyphen", label)
+	}
+	isASCII := true
+	for _, r := range label {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+		case r >= utf8.RuneSelf && (unicode.IsLower(r) || unicode.Is(unicode.Lo, r) || unicode.Is(unicode.Lm, r) || unicode.Is(unicode.Mn, r)):
+			isASCII = false
+		default:
+			return "", fmt.Errorf("label %q contains %q", label, r)
+		}
+	}
+	switch {
+	case isASCII && ascii != label:
+		return "", fmt.Errorf("label %q is punycode for an ASCII label", ascii)
+	case !isASCII && ascii == label:
+		ascii = "xn--" + punycodeEncode(label)
+	}
+	if len(ascii) > hostnameMaxLabelLen {
+		return "", fmt.Errorf("label %q is longer than %d characters", ascii, hostnameMaxLabelLen)
+	}
+	return ascii, nil
+}
+
+// checkHomograph rejects a Unicode label which mixes scripts or consists
+// only of lookalikes of ASCII characters.
+func checkHomograph(label string) error {
+	if strings.HasPrefix(label, "xn--") {
+		label, _ = punycodeDecode(label[4:])
+	}
+	scripts := map[string]bool{}
+	isASCII := true
+	for _, r := range label {
+		if s := runeScript(r); s != "" {
+			scripts[s] = true
+		}
+		isASCII = isASCII && r < utf8.RuneSelf
+	}
+	if isASCII {
+		return nil
+	}
+	if len(scripts) > 1 && !allowedScriptSet(scripts) {
+		return fmt.Errorf("label %q mixes scripts", label)
+	}
+	if skeleton := hostnameSkeletonLabel(label); isASCIIString(skeleton) {
+		return fmt.Errorf("label %q looks like %q", label, skeleton)
+	}
+	return nil
+}
+
+func allowedScriptSet(scripts map[string]bool) bool {
+	for _, set := range hostnameScriptSets {
+		n := 0
+		for _, s := range set {
+			if scripts[s] {
+				n++
+			}
+		}
+		if n == len(scripts) {
+			return true
+		}
+	}
+	return false
+}
+
+func isASCIIString(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+func hostnameSkeletonLabel(label string) string {
+	return strings.Map(func(r rune) rune {
+		if c, ok := hostnameConfusables[r]; ok {
+			return c
+		}
+		return r
+	}, label)
+}
+
+// HostnameSkeleton returns the form of name in which confusable characters
+// are replaced by the ASCII character they resemble, after decoding
+// punycode, so "pаypal.i2p" with a Cyrillic а and "paypa1.i2p" both become
+// "paypal.i2p". Names with the same skeleton are likely to be mistaken for
+// one another; an address book importer should reject or flag a new name
+// whose skeleton matches an existing one.
+func HostnameSkeleton(name string) string {
+	labels := strings.Split(strings.ToLower(name), ".")
+	for i, label := range labels {
+		if strings.HasPrefix(label, "xn--") {
+			if u, err := punycodeDecode(label[4:]); err == nil {
+				label = u
+			}
+		}
+		labels[i] = hostnameSkeletonLabel(label)
+	}
+	return strings.Join(labels, ".")
+}
+
+// HostnameToUnicode validates name and returns it with punycode labels
+// decoded, for display.
+func HostnameToUnicode(name string) (string, error) {
+	host, err := ValidateHostname(name)
+	if err != nil {
+		return "", err
+	}
+	labels := strings.Split(host, ".")
+	for i, label := range labels {
+		if strings.HasPrefix(label, "xn--") {
+			labels[i], _ = punycodeDecode(label[4:])
+		}
+	}
+	return strings.Join(labels, "."), nil
+}
@@ -0,0 +1,116 @@
+package i2pkeys
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_ValidateHostname(t *testing.T) {
+	t.Run("Punycode vectors", func(t *testing.T) {
+		// RFC 3492 section 7.1, samples A and L
+		vectors := map[string]string{
+			"ليهمابتكلموشعربي؟": "egbpdaj6bu4bxfgehfvwxn",
+			"3年B組金八先生":          "3B-ww4c5e180e575a65lsy2b",
+			"bücher":            "bcher-kva",
+		}
+		for u, p := range vectors {
+			if got := punycodeEncode(u); got != p {
+				t.Errorf("punycodeEncode(%q) = %q, want %q", u, got, p)
+			}
+			if got, err := punycodeDecode(p); err != nil || got != u {
+				t.Errorf("punycodeDecode(%q) = %q, %v", p, got, err)
+			}
+		}
+		if _, err := punycodeDecode("99999999999"); err == nil {
+			t.Error("punycodeDecode should have failed on overflow")
+		}
+	})
+
+	t.Run("Valid hostnames", func(t *testing.T) {
+		valid := map[string]string{
+			"example.i2p":           "example.i2p",
+			"Forum.Example.I2P":     "forum.example.i2p",
+			"a-b.i2p":               "a-b.i2p",
+			"123.i2p":               "123.i2p",
+			"münchen.i2p":           "xn--mnchen-3ya.i2p",
+			"xn--mnchen-3ya.i2p":    "xn--mnchen-3ya.i2p",
+			"пример.i2p":            "xn--e1afmkfd.i2p",
+			"a.b.c.example.i2p":     "a.b.c.example.i2p",
+			"www.xn--bcher-kva.i2p": "www.xn--bcher-kva.i2p",
+		}
+		for in, want := range valid {
+			got, err := ValidateHostname(in)
+			if err != nil || got != want {
+				t.Errorf("ValidateHostname(%q) = %q, %v, want %q", in, got, err, want)
+			}
+		}
+		if u, err := HostnameToUnicode("xn--mnchen-3ya.i2p"); err != nil || u != "münchen.i2p" {
+			t.Errorf("HostnameToUnicode = %q, %v", u, err)
+		}
+	})
+
+	t.Run("Invalid hostnames", func(t *testing.T) {
+		for _, in := range []string{
+			"example.com",
+			".i2p",
+			"i2p",
+			"a..b.i2p",
+			"-example.i2p",
+			"example-.i2p",
+			"ex--ample.i2p",
+			"ex_ample.i2p",
+			"ex ample.i2p",
+			"a.b.c.d.example.i2p",
+			strings.Repeat("a", 64) + ".i2p",
+			strings.Repeat("abcdefghij.", 6) + "i2p",
+			"xn--bcher-kvb.i2p",
+			"xn--example-.i2p",
+			"xn--zz.i2p",
+		} {
+			if _, err := ValidateHostname(in); !errors.Is(err, ErrInvalidHostname) {
+				t.Errorf("ValidateHostname(%q) = %v, want ErrInvalidHostname", in, err)
+			}
+		}
+	})
+
+	t.Run("Reserved hostnames", func(t *testing.T) {
+		for _, in := range []string{
+			"ukeu3k5oycgaauneqgtnvselmt4yemvoilkln7jpvamvfx7dnkdq.b32.i2p",
+			"proxy.i2p",
+			"foo.router.i2p",
+			"Console.i2p",
+		} {
+			if _, err := ValidateHostname(in); !errors.Is(err, ErrReservedHostname) {
+				t.Errorf("ValidateHostname(%q) = %v, want ErrReservedHostname", in, err)
+			}
+		}
+	})
+
+	t.Run("Homographs", func(t *testing.T) {
+		for _, in := range []string{
+			"pаypal.i2p",         // Cyrillic а among Latin
+			"аре.i2p",            // entirely Cyrillic lookalikes of "ape"
+			"xn--80ak6aa92e.i2p", // punycode of the Cyrillic "apple"
+			"ѕtats.i2p",
+		} {
+			if _, err := ValidateHostname(in); !errors.Is(err, ErrConfusableHostname) {
+				t.Errorf("ValidateHostname(%q) = %v, want ErrConfusableHostname", in, err)
+			}
+		}
+		if _, err := ValidateHostname("東京タワー.i2p"); err != nil {
+			t.Errorf("ValidateHostname rejected a Japanese name: %v", err)
+		}
+	})
+
+	t.Run("Skeletons", func(t *testing.T) {
+		for _, in := range []string{"paypal.i2p", "pаypal.i2p", "paypa1.i2p", "PAYPAL.i2p"} {
+			if s := HostnameSkeleton(in); s != "paypal.i2p" {
+				t.Errorf("HostnameSkeleton(%q) = %q", in, s)
+			}
+		}
+		if HostnameSkeleton("xn--mnchen-3ya.i2p") != "münchen.i2p" {
+			t.Error("HostnameSkeleton did not decode punycode")
+		}
+	})
+}
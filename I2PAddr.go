@@ -23,6 +23,9 @@ var (
 )
 
 // If you set this to true, Addr will return a base64 String()
+//
+// Deprecated: the switch is global to the binary and racy. Use Base32 or
+// Base64 explicitly, or the %s and %+s verbs of I2PAddr's Format method.
 var StringIsBase64 bool
 
 // The public and private keys associated with an I2P destination. I2P hides the
@@ -206,10 +209,6 @@ func (k I2PKeys) Ed25519PrivateKey() *ed25519.PrivateKey {
 	return k.SecretKey().(*ed25519.PrivateKey)
 }*/
 
-//func (k I2PKeys) Decrypt(rand io.Reader, msg []byte, opts crypto.DecrypterOpts) (plaintext []byte, err error) {
-//return k.SecretKey().(*ed25519.PrivateKey).Decrypt(rand, msg, opts)
-//}
-
 func (k I2PKeys) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) (signature []byte, err error) {
 	return k.SecretKey().(*ed25519.PrivateKey).Sign(rand, digest, opts)
 }
@@ -247,6 +246,10 @@ func DestHashFromString(str string) (dhash I2PDestHash, err error) {
 		if err != nil {
 			log.WithError(err).Error("Error decoding base32 address")
 		}
+	} else if IsBlindedAddress(str) {
+		// b33, there is no hash to decode
+		err = errors.New("blinded address has no desthash, use NewBlindedAddressFromString")
+		log.WithError(err).Error("Invalid desthash format")
 	} else {
 		// invalid
 		err = errors.New("invalid desthash format")
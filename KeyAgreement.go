@@ -0,0 +1,57 @@
+package i2pkeys
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+const keyAgreementInfo = "i2pkeys key agreement v1"
+
+// SharedSecret derives a secret of length n shared between these keys and
+// the destination peer, which the peer derives the same way from its keys
+// and our address. Both sides need an X25519 encryption key or an Ed25519
+// signing key, see Encrypt; keys with an offline signature need the former.
+// The X25519 result is expanded with HKDF-SHA256, salted with both public
+// keys and bound to label, so that different labels give independent keys
+// for different purposes:
+//
+//	key, err := keys.SharedSecret(peer, "myapp control channel", 32)
+func (k I2PKeys) SharedSecret(peer I2PAddr, label string, n int) ([]byte, error) {
+	log.WithField("peer", peer.Base32()).WithField("label", label).Debug("Deriving shared secret")
+	if n < 16 || n > 255*sha256.Size {
+		return nil, fmt.Errorf("invalid shared secret length %d", n)
+	}
+	p, err := k.privateKeyFile()
+	if err != nil {
+		return nil, err
+	}
+	priv, err := p.x25519PrivateKey()
+	if err != nil {
+		return nil, fmt.Errorf("our keys do not support key agreement: %w", err)
+	}
+	kc, err := peer.keysAndCert()
+	if err != nil {
+		return nil, err
+	}
+	pub, err := kc.x25519PublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("peer does not support key agreement: %w", err)
+	}
+	ours, theirs := priv.PublicKey().Bytes(), pub.Bytes()
+	if bytes.Equal(ours, theirs) {
+		return nil, errors.New("cannot agree on a key with ourselves")
+	}
+	shared, err := priv.ECDH(pub)
+	if err != nil {
+		return nil, fmt.Errorf("error in key agreement: %w", err)
+	}
+	defer wipe(shared)
+	// Order the keys so that both sides use the same salt.
+	if bytes.Compare(ours, theirs) > 0 {
+		ours, theirs = theirs, ours
+	}
+	salt := append(append([]byte{}, ours...), theirs...)
+	return hkdfSHA256(shared, salt, keyAgreementInfo+"\x00"+label, n)
+}
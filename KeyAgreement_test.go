@@ -0,0 +1,70 @@
+package i2pkeys
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_SharedSecret(t *testing.T) {
+	alice, err := GenerateDestination(SigTypeEd25519, CryptoTypeX25519)
+	if err != nil {
+		t.Fatalf("GenerateDestination failed: %v", err)
+	}
+	bob, err := GenerateDestination(SigTypeEd25519, CryptoTypeElGamal)
+	if err != nil {
+		t.Fatalf("GenerateDestination failed: %v", err)
+	}
+
+	t.Run("Both sides agree", func(t *testing.T) {
+		a, err := alice.SharedSecret(bob.Addr(), "test", 32)
+		if err != nil {
+			t.Fatalf("SharedSecret failed: %v", err)
+		}
+		b, err := bob.SharedSecret(alice.Addr(), "test", 32)
+		if err != nil {
+			t.Fatalf("SharedSecret failed: %v", err)
+		}
+		if !bytes.Equal(a, b) || len(a) != 32 {
+			t.Errorf("Shared secrets differ: %x %x", a, b)
+		}
+	})
+
+	t.Run("Labels separate keys", func(t *testing.T) {
+		a, _ := alice.SharedSecret(bob.Addr(), "encryption", 32)
+		b, _ := alice.SharedSecret(bob.Addr(), "authentication", 32)
+		if bytes.Equal(a, b) {
+			t.Error("Different labels gave the same secret")
+		}
+	})
+
+	t.Run("Unsupported key types", func(t *testing.T) {
+		carol, err := GenerateDestination(SigTypeECDSASHA256P256, CryptoTypeElGamal)
+		if err != nil {
+			t.Fatalf("GenerateDestination failed: %v", err)
+		}
+		if _, err := alice.SharedSecret(carol.Addr(), "test", 32); !errors.Is(err, ErrUnsupportedCryptoType) {
+			t.Errorf("Expected ErrUnsupportedCryptoType for the peer, got %v", err)
+		}
+		if _, err := carol.SharedSecret(alice.Addr(), "test", 32); !errors.Is(err, ErrUnsupportedCryptoType) {
+			t.Errorf("Expected ErrUnsupportedCryptoType for our keys, got %v", err)
+		}
+		if _, err := alice.SharedSecret(alice.Addr(), "test", 32); err == nil {
+			t.Error("SharedSecret with ourselves should have failed")
+		}
+	})
+
+	t.Run("Offline keys", func(t *testing.T) {
+		offline, err := bob.NewOfflineKeys(SigTypeEd25519, time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatalf("NewOfflineKeys failed: %v", err)
+		}
+		if secret, err := offline.SharedSecret(alice.Addr(), "test", 32); err == nil {
+			t.Errorf("SharedSecret should have failed without the Ed25519 key, got %x", secret)
+		}
+		if _, err := alice.SharedSecret(offline.Addr(), "test", 32); err != nil {
+			t.Errorf("SharedSecret with an offline signed peer failed: %v", err)
+		}
+	})
+}
@@ -0,0 +1,353 @@
+package i2pkeys
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// LeaseSetType is the network database store type of a LeaseSet.
+type LeaseSetType byte
+
+const (
+	LeaseSetTypeLeaseSet  LeaseSetType = 1
+	LeaseSetTypeLeaseSet2 LeaseSetType = 3
+	LeaseSetTypeEncrypted LeaseSetType = 5
+	LeaseSetTypeMeta      LeaseSetType = 7
+)
+
+func (t LeaseSetType) String() string {
+	switch t {
+	case LeaseSetTypeLeaseSet:
+		return "LeaseSet"
+	case LeaseSetTypeLeaseSet2:
+		return "LeaseSet2"
+	case LeaseSetTypeEncrypted:
+		return "EncryptedLeaseSet"
+	case LeaseSetTypeMeta:
+		return "MetaLeaseSet"
+	}
+	return "LeaseSetType(" + strconv.Itoa(int(t)) + ")"
+}
+
+const (
+	ls2FlagOfflineKeys uint16 = 1 << 0
+	ls2FlagUnpublished uint16 = 1 << 1
+	ls2FlagBlinded     uint16 = 1 << 2
+
+	lease1Len     = 44
+	lease2Len     = 40
+	metaLeaseLen  = 40
+	revocationLen = 32
+)
+
+// RouterHash is the SHA-256 hash of a router identity.
+type RouterHash [32]byte
+
+// String returns the hash in I2P's base64 alphabet, the way routers are
+// usually identified.
+func (h RouterHash) String() string {
+	return i2pB64enc.EncodeToString(h[:])
+}
+
+// Lease is an inbound tunnel of a destination.
+type Lease struct {
+	Gateway  RouterHash
+	TunnelID uint32
+	EndDate  time.Time
+}
+
+// MetaLease points a MetaLeaseSet at another LeaseSet.
+type MetaLease struct {
+	Hash    I2PDestHash
+	Type    LeaseSetType // 0 if unknown
+	Cost    byte
+	EndDate time.Time
+}
+
+// LeaseSet is a decoded LeaseSet, LeaseSet2, EncryptedLeaseSet or
+// MetaLeaseSet. Fields which do not occur in a type are left empty: an
+// EncryptedLeaseSet only carries its blinded key and encrypted data, and the
+// original LeaseSet has no options or publication date.
+type LeaseSet struct {
+	Type             LeaseSetType
+	Destination      I2PAddr // empty for EncryptedLeaseSet
+	Published        time.Time
+	Expires          time.Time // for LeaseSet, the latest lease end date
+	Unpublished      bool
+	Blinded          bool
+	OfflineSignature *OfflineSignature
+	Options          map[string]string
+	EncryptionKeys   []EncryptionKeyPair // PrivateKey is always nil
+	Leases           []Lease
+	MetaLeases       []MetaLease
+	Revocations      []I2PDestHash
+	BlindedSigType   SigType // EncryptedLeaseSet only
+	BlindedPublicKey []byte  // EncryptedLeaseSet only
+	EncryptedData    []byte  // EncryptedLeaseSet only
+	Signature        []byte
+
+	signed []byte // data covered by Signature
+}
+
+// ReadLeaseSet decodes a LeaseSet of type t, as found in a DatabaseStore
+// message or dumped by a router. It does not check the signature, see
+// Verify.
+func ReadLeaseSet(t LeaseSetType, b []byte) (*LeaseSet, error) {
+	log.WithField("type", t).WithField("length", len(b)).Debug("Reading LeaseSet")
+	ls := &LeaseSet{Type: t}
+	var (
+		rest []byte
+		err  error
+	)
+	switch t {
+	case LeaseSetTypeLeaseSet:
+		rest, err = ls.readLeaseSet1(b)
+	case LeaseSetTypeLeaseSet2, LeaseSetTypeMeta:
+		rest, err = ls.readLeaseSet2(b)
+	case LeaseSetTypeEncrypted:
+		rest, err = ls.readEncrypted(b)
+	default:
+		return nil, fmt.Errorf("unknown LeaseSet type %d", t)
+	}
+	if err != nil {
+		log.WithError(err).Error("Error reading LeaseSet")
+		return nil, fmt.Errorf("invalid %s: %w", t, err)
+	}
+	sigType := ls.signatureType()
+	if sigType.SignatureLen() == 0 {
+		return nil, fmt.Errorf("invalid %s: %w %s", t, ErrUnsupportedSigType, sigType)
+	}
+	if len(rest) != sigType.SignatureLen() {
+		return nil, fmt.Errorf("invalid %s: signature length %d, want %d", t, len(rest), sigType.SignatureLen())
+	}
+	ls.Signature = rest
+	ls.signed = b[:len(b)-len(rest)]
+	if t != LeaseSetTypeLeaseSet {
+		// LS2 signatures also cover the store type.
+		ls.signed = append([]byte{byte(t)}, ls.signed...)
+	}
+	return ls, nil
+}
+
+func (ls *LeaseSet) readLeaseSet1(b []byte) ([]byte, error) {
+	dest, err := readKeysAndCert(b)
+	if err != nil {
+		return nil, fmt.Errorf("error reading destination: %w", err)
+	}
+	ls.Destination = I2PAddr(i2pB64enc.EncodeToString(dest.raw))
+	rest := b[len(dest.raw):]
+	var key []byte
+	if key, rest, err = readBytes(rest, CryptoTypeElGamal.PublicKeyLen(), "encryption key"); err != nil {
+		return nil, err
+	}
+	ls.EncryptionKeys = []EncryptionKeyPair{{Type: CryptoTypeElGamal, PublicKey: key}}
+	// The signing key of the original LeaseSet is unused.
+	if _, rest, err = readBytes(rest, dest.sigType.PublicKeyLen(), "signing key"); err != nil {
+		return nil, err
+	}
+	num, rest, err := readBytes(rest, 1, "lease count")
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < int(num[0]); i++ {
+		var l []byte
+		if l, rest, err = readBytes(rest, lease1Len, "lease"); err != nil {
+			return nil, err
+		}
+		lease := Lease{TunnelID: binary.BigEndian.Uint32(l[32:36]), EndDate: time.UnixMilli(int64(binary.BigEndian.Uint64(l[36:44])))}
+		copy(lease.Gateway[:], l[:32])
+		ls.Leases = append(ls.Leases, lease)
+		if lease.EndDate.After(ls.Expires) {
+			ls.Expires = lease.EndDate
+		}
+	}
+	return rest, nil
+}
+
+// readHeader decodes the published and expires times and the flags shared by
+// the LS2 types, and the offline signature which may follow them.
+func (ls *LeaseSet) readHeader(b []byte, signerType SigType) ([]byte, error) {
+	hdr, rest, err := readBytes(b, 8, "header")
+	if err != nil {
+		return nil, err
+	}
+	ls.Published = time.Unix(int64(binary.BigEndian.Uint32(hdr[0:4])), 0)
+	ls.Expires = ls.Published.Add(time.Duration(binary.BigEndian.Uint16(hdr[4:6])) * time.Second)
+	flags := binary.BigEndian.Uint16(hdr[6:8])
+	ls.Unpublished = flags&ls2FlagUnpublished != 0
+	ls.Blinded = flags&ls2FlagBlinded != 0
+	if flags&ls2FlagOfflineKeys != 0 {
+		o, r, err := ReadOfflineSignature(rest, signerType)
+		if err != nil {
+			return nil, err
+		}
+		ls.OfflineSignature, rest = &o, r
+	}
+	return rest, nil
+}
+
+func (ls *LeaseSet) readLeaseSet2(b []byte) ([]byte, error) {
+	dest, err := readKeysAndCert(b)
+	if err != nil {
+		return nil, fmt.Errorf("error reading destination: %w", err)
+	}
+	ls.Destination = I2PAddr(i2pB64enc.EncodeToString(dest.raw))
+	rest, err := ls.readHeader(b[len(dest.raw):], dest.sigType)
+	if err != nil {
+		return nil, err
+	}
+	if ls.Options, rest, err = readMapping(rest); err != nil {
+		return nil, err
+	}
+	if ls.Type == LeaseSetTypeMeta {
+		return ls.readMetaBody(rest)
+	}
+	num, rest, err := readBytes(rest, 1, "encryption key count")
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < int(num[0]); i++ {
+		var hdr, key []byte
+		if hdr, rest, err = readBytes(rest, 4, "encryption key header"); err != nil {
+			return nil, err
+		}
+		if key, rest, err = readBytes(rest, int(binary.BigEndian.Uint16(hdr[2:4])), "encryption key"); err != nil {
+			return nil, err
+		}
+		ls.EncryptionKeys = append(ls.EncryptionKeys, EncryptionKeyPair{Type: CryptoType(binary.BigEndian.Uint16(hdr[0:2])), PublicKey: key})
+	}
+	if num, rest, err = readBytes(rest, 1, "lease count"); err != nil {
+		return nil, err
+	}
+	for i := 0; i < int(num[0]); i++ {
+		var l []byte
+		if l, rest, err = readBytes(rest, lease2Len, "lease"); err != nil {
+			return nil, err
+		}
+		lease := Lease{TunnelID: binary.BigEndian.Uint32(l[32:36]), EndDate: time.Unix(int64(binary.BigEndian.Uint32(l[36:40])), 0)}
+		copy(lease.Gateway[:], l[:32])
+		ls.Leases = append(ls.Leases, lease)
+	}
+	return rest, nil
+}
+
+func (ls *LeaseSet) readMetaBody(b []byte) ([]byte, error) {
+	num, rest, err := readBytes(b, 1, "lease count")
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < int(num[0]); i++ {
+		var l []byte
+		if l, rest, err = readBytes(rest, metaLeaseLen, "meta lease"); err != nil {
+			return nil, err
+		}
+		ml := MetaLease{Type: LeaseSetType(l[34] & 0x0f), Cost: l[35], EndDate: time.Unix(int64(binary.BigEndian.Uint32(l[36:40])), 0)}
+		copy(ml.Hash[:], l[:32])
+		ls.MetaLeases = append(ls.MetaLeases, ml)
+	}
+	if num, rest, err = readBytes(rest, 1, "revocation count"); err != nil {
+		return nil, err
+	}
+	for i := 0; i < int(num[0]); i++ {
+		var h []byte
+		if h, rest, err = readBytes(rest, revocationLen, "revocation"); err != nil {
+			return nil, err
+		}
+		ls.Revocations = append(ls.Revocations, I2PDestHash(h))
+	}
+	return rest, nil
+}
+
+func (ls *LeaseSet) readEncrypted(b []byte) ([]byte, error) {
+	st, rest, err := readBytes(b, 2, "blinded signature type")
+	if err != nil {
+		return nil, err
+	}
+	ls.BlindedSigType = SigType(binary.BigEndian.Uint16(st))
+	if ls.BlindedSigType.PublicKeyLen() == 0 {
+		return nil, fmt.Errorf("%w %s", ErrUnsupportedSigType, ls.BlindedSigType)
+	}
+	if ls.BlindedPublicKey, rest, err = readBytes(rest, ls.BlindedSigType.PublicKeyLen(), "blinded public key"); err != nil {
+		return nil, err
+	}
+	if rest, err = ls.readHeader(rest, ls.BlindedSigType); err != nil {
+		return nil, err
+	}
+	n, rest, err := readBytes(rest, 2, "encrypted data length")
+	if err != nil {
+		return nil, err
+	}
+	ls.EncryptedData, rest, err = readBytes(rest, int(binary.BigEndian.Uint16(n)), "encrypted data")
+	return rest, err
+}
+
+// signer returns the type and key of the long-term key of the LeaseSet: the
+// destination's signing key, or the blinded key of an EncryptedLeaseSet.
+func (ls *LeaseSet) signer() (SigType, []byte, error) {
+	if ls.Type == LeaseSetTypeEncrypted {
+		return ls.BlindedSigType, ls.BlindedPublicKey, nil
+	}
+	kc, err := ls.Destination.keysAndCert()
+	if err != nil {
+		return 0, nil, err
+	}
+	return kc.sigType, kc.signingKey, nil
+}
+
+// signatureType returns the type of the key which signed the LeaseSet.
+func (ls *LeaseSet) signatureType() SigType {
+	if ls.OfflineSignature != nil {
+		return ls.OfflineSignature.TransientType
+	}
+	t, _, err := ls.signer()
+	if err != nil {
+		return 0
+	}
+	return t
+}
+
+// Verify checks the signature of the LeaseSet, and for offline signed
+// LeaseSets that the transient key was signed by the destination (or
+// blinded key) and has not expired. It does not check whether the LeaseSet
+// itself has expired, see Expired.
+func (ls *LeaseSet) Verify() error {
+	sigType, key, err := ls.signer()
+	if err != nil {
+		return err
+	}
+	if o := ls.OfflineSignature; o != nil {
+		if err := VerifySignature(sigType, key, o.signedBytes(), o.Signature); err != nil {
+			return fmt.Errorf("offline signature: %w", err)
+		}
+		if o.Expired(time.Now()) {
+			return fmt.Errorf("offline signature expired at %s", o.Expires.UTC().Format(time.RFC3339))
+		}
+		sigType, key = o.TransientType, o.TransientPublicKey
+	}
+	if len(ls.signed) == 0 {
+		return errors.New("LeaseSet was not read with ReadLeaseSet")
+	}
+	if err := VerifySignature(sigType, key, ls.signed, ls.Signature); err != nil {
+		return fmt.Errorf("%s signature: %w", ls.Type, err)
+	}
+	return nil
+}
+
+// Expired reports whether the LeaseSet is no longer valid at t.
+func (ls *LeaseSet) Expired(t time.Time) bool {
+	return !t.Before(ls.Expires)
+}
+
+// DestHash returns the hash the LeaseSet is stored under: the destination
+// hash, or SHA-256 of the blinded key for an EncryptedLeaseSet.
+func (ls *LeaseSet) DestHash() I2PDestHash {
+	if ls.Type == LeaseSetTypeEncrypted {
+		data := binary.BigEndian.AppendUint16(nil, uint16(ls.BlindedSigType))
+		return I2PDestHash(sha256.Sum256(append(data, ls.BlindedPublicKey...)))
+	}
+	return ls.Destination.DestHash()
+}
@@ -0,0 +1,147 @@
+package i2pkeys
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// maxLeases is the most leases a LeaseSet2 or MetaLeaseSet may contain.
+const maxLeases = 16
+
+// appendLeaseSet2Body encodes the encryption keys and leases of a LeaseSet2.
+func (ls *LeaseSet) appendLeaseSet2Body(b []byte) ([]byte, error) {
+	if len(ls.EncryptionKeys) == 0 || len(ls.EncryptionKeys) > 255 {
+		return nil, fmt.Errorf("invalid number of encryption keys %d", len(ls.EncryptionKeys))
+	}
+	b = append(b, byte(len(ls.EncryptionKeys)))
+	for _, k := range ls.EncryptionKeys {
+		if n := k.Type.PublicKeyLen(); (n != 0 && len(k.PublicKey) != n) || len(k.PublicKey) == 0 || len(k.PublicKey) > 0xffff {
+			return nil, fmt.Errorf("invalid %s encryption key length %d", k.Type, len(k.PublicKey))
+		}
+		b = append(b, k.Bytes()...)
+	}
+	if len(ls.Leases) > maxLeases {
+		return nil, fmt.Errorf("too many leases: %d, at most %d", len(ls.Leases), maxLeases)
+	}
+	b = append(b, byte(len(ls.Leases)))
+	for _, l := range ls.Leases {
+		end, err := unixSeconds(l.EndDate)
+		if err != nil {
+			return nil, fmt.Errorf("lease end date: %w", err)
+		}
+		b = append(b, l.Gateway[:]...)
+		b = binary.BigEndian.AppendUint32(b, l.TunnelID)
+		b = binary.BigEndian.AppendUint32(b, end)
+	}
+	return b, nil
+}
+
+// appendMetaBody encodes the meta leases and revocations of a MetaLeaseSet.
+func (ls *LeaseSet) appendMetaBody(b []byte) ([]byte, error) {
+	if len(ls.MetaLeases) > maxLeases {
+		return nil, fmt.Errorf("too many leases: %d, at most %d", len(ls.MetaLeases), maxLeases)
+	}
+	b = append(b, byte(len(ls.MetaLeases)))
+	for _, l := range ls.MetaLeases {
+		if l.Type > 0x0f {
+			return nil, fmt.Errorf("invalid meta lease type %d", l.Type)
+		}
+		end, err := unixSeconds(l.EndDate)
+		if err != nil {
+			return nil, fmt.Errorf("lease end date: %w", err)
+		}
+		b = append(b, l.Hash[:]...)
+		b = append(b, 0, 0, byte(l.Type), l.Cost)
+		b = binary.BigEndian.AppendUint32(b, end)
+	}
+	if len(ls.Revocations) > 255 {
+		return nil, fmt.Errorf("too many revocations: %d", len(ls.Revocations))
+	}
+	b = append(b, byte(len(ls.Revocations)))
+	for _, h := range ls.Revocations {
+		b = append(b, h[:]...)
+	}
+	return b, nil
+}
+
+// unixSeconds converts t to the four byte seconds timestamp used by LS2.
+func unixSeconds(t time.Time) (uint32, error) {
+	if t.Unix() <= 0 || t.Unix() > 0xffffffff {
+		return 0, fmt.Errorf("time %s out of range", t)
+	}
+	return uint32(t.Unix()), nil
+}
+
+// SignLeaseSet encodes ls, which must be a LeaseSet2 or MetaLeaseSet, for
+// the destination of the keys and signs it. The Destination,
+// OfflineSignature and Signature fields of ls are set from the keys; keys
+// with an offline signature sign with their transient key. Times are
+// truncated to seconds, Expires may be at most 65535 seconds after
+// Published. The result is accepted by ReadLeaseSet, and ls.Verify succeeds
+// afterwards.
+func (k I2PKeys) SignLeaseSet(ls *LeaseSet) ([]byte, error) {
+	log.WithField("type", ls.Type).WithField("addr", k.Address.Base32()).Debug("Signing LeaseSet")
+	if ls.Type != LeaseSetTypeLeaseSet2 && ls.Type != LeaseSetTypeMeta {
+		return nil, fmt.Errorf("cannot build a %s", ls.Type)
+	}
+	p, err := k.privateKeyFile()
+	if err != nil {
+		return nil, err
+	}
+	published, err := unixSeconds(ls.Published)
+	if err != nil {
+		return nil, fmt.Errorf("published: %w", err)
+	}
+	expires := ls.Expires.Unix() - int64(published)
+	if expires <= 0 || expires > 0xffff {
+		return nil, fmt.Errorf("expires must be 1 to 65535 seconds after published, is %d", expires)
+	}
+	var flags uint16
+	if p.offline != nil {
+		flags |= ls2FlagOfflineKeys
+	}
+	if ls.Unpublished {
+		flags |= ls2FlagUnpublished
+	}
+	if ls.Blinded {
+		flags |= ls2FlagBlinded
+	}
+
+	b := []byte{byte(ls.Type)}
+	b = append(b, p.dest.raw...)
+	b = binary.BigEndian.AppendUint32(b, published)
+	b = binary.BigEndian.AppendUint16(b, uint16(expires))
+	b = binary.BigEndian.AppendUint16(b, flags)
+	if p.offline != nil {
+		b = append(b, p.offline.Bytes()...)
+	}
+	if b, err = appendMapping(b, ls.Options); err != nil {
+		return nil, err
+	}
+	if ls.Type == LeaseSetTypeMeta {
+		b, err = ls.appendMetaBody(b)
+	} else {
+		b, err = ls.appendLeaseSet2Body(b)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ls.Type, err)
+	}
+	sigType, priv := p.signingKey()
+	sig, err := signMessage(sigType, priv, b)
+	if err != nil {
+		return nil, err
+	}
+
+	ls.Destination = I2PAddr(i2pB64enc.EncodeToString(p.dest.raw))
+	ls.OfflineSignature = nil
+	if p.offline != nil {
+		o := p.offline.OfflineSignature
+		ls.OfflineSignature = &o
+	}
+	ls.Published = time.Unix(int64(published), 0)
+	ls.Expires = ls.Published.Add(time.Duration(expires) * time.Second)
+	ls.Signature = sig
+	ls.signed = b
+	return append(append([]byte{}, b[1:]...), sig...), nil
+}
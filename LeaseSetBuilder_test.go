@@ -0,0 +1,109 @@
+package i2pkeys
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_SignLeaseSet(t *testing.T) {
+	keys, err := GenerateDestination(SigTypeEd25519, CryptoTypeX25519)
+	if err != nil {
+		t.Fatalf("GenerateDestination failed: %v", err)
+	}
+	enc, _ := GenerateEncryptionKey(CryptoTypeX25519)
+	published := time.Now().Truncate(time.Second)
+	var gateway RouterHash
+	copy(gateway[:], randomBytes(t, 32))
+
+	t.Run("LeaseSet2 round trip", func(t *testing.T) {
+		ls := &LeaseSet{
+			Type:           LeaseSetTypeLeaseSet2,
+			Published:      published,
+			Expires:        published.Add(10 * time.Minute),
+			Options:        map[string]string{"s": "_http._tcp 0 0 80 example.i2p"},
+			EncryptionKeys: []EncryptionKeyPair{{Type: enc.Type, PublicKey: enc.PublicKey}},
+			Leases:         []Lease{{Gateway: gateway, TunnelID: 42, EndDate: published.Add(10 * time.Minute)}},
+		}
+		b, err := keys.SignLeaseSet(ls)
+		if err != nil {
+			t.Fatalf("SignLeaseSet failed: %v", err)
+		}
+		if err := ls.Verify(); err != nil {
+			t.Errorf("Verify of built LeaseSet2 failed: %v", err)
+		}
+		got, err := ReadLeaseSet(LeaseSetTypeLeaseSet2, b)
+		if err != nil {
+			t.Fatalf("ReadLeaseSet failed: %v", err)
+		}
+		if err := got.Verify(); err != nil {
+			t.Errorf("Verify of parsed LeaseSet2 failed: %v", err)
+		}
+		if !reflect.DeepEqual(got, ls) {
+			t.Errorf("Parsed LeaseSet2 differs:\n got %+v\nwant %+v", got, ls)
+		}
+	})
+
+	t.Run("MetaLeaseSet with offline keys", func(t *testing.T) {
+		transient, err := keys.NewOfflineKeys(SigTypeEd25519, time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatalf("NewOfflineKeys failed: %v", err)
+		}
+		ls := &LeaseSet{
+			Type:        LeaseSetTypeMeta,
+			Published:   published,
+			Expires:     published.Add(time.Hour),
+			Options:     map[string]string{},
+			MetaLeases:  []MetaLease{{Hash: keys.Addr().DestHash(), Type: LeaseSetTypeLeaseSet2, Cost: 1, EndDate: published.Add(time.Hour)}},
+			Revocations: []I2PDestHash{I2PDestHash(gateway)},
+		}
+		b, err := transient.SignLeaseSet(ls)
+		if err != nil {
+			t.Fatalf("SignLeaseSet failed: %v", err)
+		}
+		got, err := ReadLeaseSet(LeaseSetTypeMeta, b)
+		if err != nil {
+			t.Fatalf("ReadLeaseSet failed: %v", err)
+		}
+		if got.OfflineSignature == nil {
+			t.Fatal("Offline signature missing")
+		}
+		if err := got.Verify(); err != nil {
+			t.Errorf("Verify failed: %v", err)
+		}
+		if !reflect.DeepEqual(got, ls) {
+			t.Errorf("Parsed MetaLeaseSet differs:\n got %+v\nwant %+v", got, ls)
+		}
+	})
+
+	t.Run("Field limits", func(t *testing.T) {
+		valid := func() *LeaseSet {
+			return &LeaseSet{
+				Type:           LeaseSetTypeLeaseSet2,
+				Published:      published,
+				Expires:        published.Add(time.Minute),
+				EncryptionKeys: []EncryptionKeyPair{enc},
+			}
+		}
+		if _, err := keys.SignLeaseSet(valid()); err != nil {
+			t.Fatalf("SignLeaseSet failed: %v", err)
+		}
+		cases := map[string]func(ls *LeaseSet){
+			"too long expiry":   func(ls *LeaseSet) { ls.Expires = published.Add(20 * time.Hour) },
+			"expired":           func(ls *LeaseSet) { ls.Expires = published },
+			"no keys":           func(ls *LeaseSet) { ls.EncryptionKeys = nil },
+			"wrong key length":  func(ls *LeaseSet) { ls.EncryptionKeys[0].PublicKey = enc.PublicKey[:31] },
+			"too many leases":   func(ls *LeaseSet) { ls.Leases = make([]Lease, 17) },
+			"long option":       func(ls *LeaseSet) { ls.Options = map[string]string{"k": string(bytes.Repeat([]byte("v"), 256))} },
+			"original LeaseSet": func(ls *LeaseSet) { ls.Type = LeaseSetTypeLeaseSet },
+		}
+		for name, modify := range cases {
+			ls := valid()
+			modify(ls)
+			if _, err := keys.SignLeaseSet(ls); err == nil {
+				t.Errorf("SignLeaseSet should have failed: %s", name)
+			}
+		}
+	})
+}
@@ -0,0 +1,208 @@
+package i2pkeys
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+)
+
+// testLS2Header builds the header shared by the LS2 types.
+func testLS2Header(prefix []byte, published time.Time, offline *OfflineSignature) []byte {
+	b := append([]byte{}, prefix...)
+	b = binary.BigEndian.AppendUint32(b, uint32(published.Unix()))
+	b = binary.BigEndian.AppendUint16(b, 600)
+	var flags uint16
+	if offline != nil {
+		flags |= ls2FlagOfflineKeys
+	}
+	b = binary.BigEndian.AppendUint16(b, flags)
+	if offline != nil {
+		b = append(b, offline.Bytes()...)
+	}
+	return b
+}
+
+func testSignedLeaseSet(t *testing.T, keys I2PKeys, lsType LeaseSetType, b []byte) []byte {
+	if lsType != LeaseSetTypeLeaseSet {
+		b = append([]byte{byte(lsType)}, b...)
+	}
+	sig, err := keys.SignMessage(b)
+	if err != nil {
+		t.Fatalf("SignMessage failed: %v", err)
+	}
+	if lsType != LeaseSetTypeLeaseSet {
+		b = b[1:]
+	}
+	return append(b, sig...)
+}
+
+func Test_LeaseSet(t *testing.T) {
+	keys, err := GenerateDestination(SigTypeEd25519, CryptoTypeX25519)
+	if err != nil {
+		t.Fatalf("GenerateDestination failed: %v", err)
+	}
+	dest, _ := keys.Addr().ToBytes()
+	published := time.Now().Truncate(time.Second)
+	gateway := randomBytes(t, 32)
+	enc, _ := GenerateEncryptionKey(CryptoTypeX25519)
+
+	t.Run("LeaseSet", func(t *testing.T) {
+		elg, _ := GenerateEncryptionKey(CryptoTypeElGamal)
+		b := append(append([]byte{}, dest...), elg.PublicKey...)
+		b = append(b, make([]byte, 32)...)
+		b = append(b, 2)
+		for i := 0; i < 2; i++ {
+			b = append(b, gateway...)
+			b = binary.BigEndian.AppendUint32(b, uint32(i))
+			b = binary.BigEndian.AppendUint64(b, uint64(published.Add(time.Duration(i)*time.Minute).UnixMilli()))
+		}
+		b = testSignedLeaseSet(t, keys, LeaseSetTypeLeaseSet, b)
+		ls, err := ReadLeaseSet(LeaseSetTypeLeaseSet, b)
+		if err != nil {
+			t.Fatalf("ReadLeaseSet failed: %v", err)
+		}
+		if ls.Destination != keys.Addr() || len(ls.Leases) != 2 || ls.Leases[1].TunnelID != 1 {
+			t.Errorf("Unexpected LeaseSet %+v", ls)
+		}
+		if !ls.Expires.Equal(published.Add(time.Minute)) {
+			t.Errorf("Expires = %s, want latest lease end", ls.Expires)
+		}
+		if err := ls.Verify(); err != nil {
+			t.Errorf("Verify failed: %v", err)
+		}
+	})
+
+	ls2 := func(t *testing.T, signer I2PKeys, offline *OfflineSignature) []byte {
+		b := testLS2Header(dest, published, offline)
+		b, err := appendMapping(b, map[string]string{"s": "http", "a": "1"})
+		if err != nil {
+			t.Fatalf("appendMapping failed: %v", err)
+		}
+		b = append(b, 1)
+		b = append(b, enc.Bytes()...)
+		b = append(b, 1)
+		b = append(b, gateway...)
+		b = binary.BigEndian.AppendUint32(b, 1234)
+		b = binary.BigEndian.AppendUint32(b, uint32(published.Add(10*time.Minute).Unix()))
+		return testSignedLeaseSet(t, signer, LeaseSetTypeLeaseSet2, b)
+	}
+
+	t.Run("LeaseSet2", func(t *testing.T) {
+		b := ls2(t, keys, nil)
+		ls, err := ReadLeaseSet(LeaseSetTypeLeaseSet2, b)
+		if err != nil {
+			t.Fatalf("ReadLeaseSet failed: %v", err)
+		}
+		if ls.Options["s"] != "http" || len(ls.EncryptionKeys) != 1 || !bytes.Equal(ls.EncryptionKeys[0].PublicKey, enc.PublicKey) {
+			t.Errorf("Unexpected LeaseSet2 %+v", ls)
+		}
+		if len(ls.Leases) != 1 || ls.Leases[0].TunnelID != 1234 || !bytes.Equal(ls.Leases[0].Gateway[:], gateway) {
+			t.Errorf("Unexpected leases %+v", ls.Leases)
+		}
+		if !ls.Expires.Equal(published.Add(600*time.Second)) || ls.Expired(published) {
+			t.Errorf("Unexpected expiry %s", ls.Expires)
+		}
+		if ls.DestHash() != keys.Addr().DestHash() {
+			t.Error("DestHash does not match destination")
+		}
+		if err := ls.Verify(); err != nil {
+			t.Errorf("Verify failed: %v", err)
+		}
+		b[len(dest)+20] ^= 1
+		if ls, err := ReadLeaseSet(LeaseSetTypeLeaseSet2, b); err == nil && ls.Verify() == nil {
+			t.Error("Verify should have failed for a modified LeaseSet2")
+		}
+	})
+
+	t.Run("Offline signature", func(t *testing.T) {
+		transient, err := keys.NewOfflineKeys(SigTypeECDSASHA256P256, time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatalf("NewOfflineKeys failed: %v", err)
+		}
+		o, err := transient.OfflineSignature()
+		if err != nil {
+			t.Fatalf("OfflineSignature failed: %v", err)
+		}
+		ls, err := ReadLeaseSet(LeaseSetTypeLeaseSet2, ls2(t, transient, o))
+		if err != nil {
+			t.Fatalf("ReadLeaseSet failed: %v", err)
+		}
+		if ls.OfflineSignature == nil || ls.OfflineSignature.TransientType != SigTypeECDSASHA256P256 {
+			t.Fatalf("Offline signature not decoded: %+v", ls.OfflineSignature)
+		}
+		if err := ls.Verify(); err != nil {
+			t.Errorf("Verify failed: %v", err)
+		}
+		if ls, err := ReadLeaseSet(LeaseSetTypeLeaseSet2, ls2(t, keys, o)); err == nil && ls.Verify() == nil {
+			t.Error("Verify should have failed for a LeaseSet2 not signed by the transient key")
+		}
+	})
+
+	t.Run("MetaLeaseSet", func(t *testing.T) {
+		b := testLS2Header(dest, published, nil)
+		b, _ = appendMapping(b, nil)
+		b = append(b, 1)
+		target := keys.Addr().DestHash()
+		b = append(b, target[:]...)
+		b = append(b, 0, 0, byte(LeaseSetTypeLeaseSet2), 5)
+		b = binary.BigEndian.AppendUint32(b, uint32(published.Add(time.Hour).Unix()))
+		b = append(b, 1)
+		b = append(b, gateway...)
+		b = testSignedLeaseSet(t, keys, LeaseSetTypeMeta, b)
+		ls, err := ReadLeaseSet(LeaseSetTypeMeta, b)
+		if err != nil {
+			t.Fatalf("ReadLeaseSet failed: %v", err)
+		}
+		if len(ls.MetaLeases) != 1 || ls.MetaLeases[0].Hash != target || ls.MetaLeases[0].Type != LeaseSetTypeLeaseSet2 || ls.MetaLeases[0].Cost != 5 {
+			t.Errorf("Unexpected meta leases %+v", ls.MetaLeases)
+		}
+		if len(ls.Revocations) != 1 || !bytes.Equal(ls.Revocations[0][:], gateway) {
+			t.Errorf("Unexpected revocations %+v", ls.Revocations)
+		}
+		if err := ls.Verify(); err != nil {
+			t.Errorf("Verify failed: %v", err)
+		}
+	})
+
+	t.Run("EncryptedLeaseSet", func(t *testing.T) {
+		pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+		prefix := binary.BigEndian.AppendUint16(nil, uint16(SigTypeRedDSAEd25519))
+		b := testLS2Header(append(prefix, pub...), published, nil)
+		data := randomBytes(t, 100)
+		b = binary.BigEndian.AppendUint16(b, uint16(len(data)))
+		b = append(b, data...)
+		b = append(b, ed25519.Sign(priv, append([]byte{byte(LeaseSetTypeEncrypted)}, b...))...)
+		ls, err := ReadLeaseSet(LeaseSetTypeEncrypted, b)
+		if err != nil {
+			t.Fatalf("ReadLeaseSet failed: %v", err)
+		}
+		if ls.Destination != "" || !bytes.Equal(ls.EncryptedData, data) || !bytes.Equal(ls.BlindedPublicKey, pub) {
+			t.Errorf("Unexpected EncryptedLeaseSet %+v", ls)
+		}
+		if err := ls.Verify(); err != nil {
+			t.Errorf("Verify failed: %v", err)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		b := ls2(t, keys, nil)
+		if _, err := ReadLeaseSet(LeaseSetTypeLeaseSet2, b[:len(b)-1]); err == nil {
+			t.Error("ReadLeaseSet should have failed for a truncated LeaseSet2")
+		}
+		if _, err := ReadLeaseSet(2, b); err == nil {
+			t.Error("ReadLeaseSet should have failed for an unknown type")
+		}
+		other, _ := GenerateDestination(SigTypeEd25519, CryptoTypeX25519)
+		ls, err := ReadLeaseSet(LeaseSetTypeLeaseSet2, ls2(t, other, nil))
+		if err != nil {
+			t.Fatalf("ReadLeaseSet failed: %v", err)
+		}
+		if err := ls.Verify(); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("Expected ErrInvalidSignature, got %v", err)
+		}
+	})
+}
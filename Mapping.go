@@ -0,0 +1,84 @@
+package i2pkeys
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// readString decodes an I2P String: a length byte followed by up to 255
+// bytes of UTF-8.
+func readString(b []byte) (string, []byte, error) {
+	n, rest, err := readBytes(b, 1, "string length")
+	if err != nil {
+		return "", nil, err
+	}
+	s, rest, err := readBytes(rest, int(n[0]), "string")
+	if err != nil {
+		return "", nil, err
+	}
+	return string(s), rest, nil
+}
+
+// readMapping decodes an I2P Mapping, a two byte size followed by
+// "key=value;" entries of I2P Strings.
+func readMapping(b []byte) (map[string]string, []byte, error) {
+	size, rest, err := readBytes(b, 2, "mapping size")
+	if err != nil {
+		return nil, nil, err
+	}
+	data, rest, err := readBytes(rest, int(binary.BigEndian.Uint16(size)), "mapping")
+	if err != nil {
+		return nil, nil, err
+	}
+	m := make(map[string]string)
+	for len(data) > 0 {
+		var k, v string
+		if k, data, err = readString(data); err != nil {
+			return nil, nil, err
+		}
+		if len(data) == 0 || data[0] != '=' {
+			return nil, nil, fmt.Errorf("mapping: missing = after %q", k)
+		}
+		if v, data, err = readString(data[1:]); err != nil {
+			return nil, nil, err
+		}
+		if len(data) == 0 || data[0] != ';' {
+			return nil, nil, fmt.Errorf("mapping: missing ; after %q", k)
+		}
+		data = data[1:]
+		if _, ok := m[k]; ok {
+			return nil, nil, fmt.Errorf("mapping: duplicate key %q", k)
+		}
+		m[k] = v
+	}
+	return m, rest, nil
+}
+
+// appendMapping encodes m as an I2P Mapping with the keys sorted, as
+// required for signed structures.
+func appendMapping(b []byte, m map[string]string) ([]byte, error) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var data []byte
+	for _, k := range keys {
+		v := m[k]
+		if len(k) > 255 || len(v) > 255 {
+			return nil, fmt.Errorf("mapping: entry %q too long", k)
+		}
+		data = append(data, byte(len(k)))
+		data = append(data, k...)
+		data = append(data, '=', byte(len(v)))
+		data = append(data, v...)
+		data = append(data, ';')
+	}
+	if len(data) > 0xffff {
+		return nil, errors.New("mapping too large")
+	}
+	b = binary.BigEndian.AppendUint16(b, uint16(len(data)))
+	return append(b, data...), nil
+}
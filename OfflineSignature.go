@@ -0,0 +1,159 @@
+package i2pkeys
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// OfflineSignature is the LS2 offline signature block: the destination's
+// long-term signing key signs a transient signing key together with an
+// expiration time, so that routers only ever need to hold the transient key.
+type OfflineSignature struct {
+	Expires            time.Time // second resolution
+	TransientType      SigType
+	TransientPublicKey []byte
+	Signature          []byte // by the destination's signing key
+}
+
+// signedBytes returns the part of the block covered by Signature.
+func (o OfflineSignature) signedBytes() []byte {
+	b := make([]byte, 6, 6+len(o.TransientPublicKey))
+	binary.BigEndian.PutUint32(b[0:4], uint32(o.Expires.Unix()))
+	binary.BigEndian.PutUint16(b[4:6], uint16(o.TransientType))
+	return append(b, o.TransientPublicKey...)
+}
+
+// Bytes returns the block as it appears in LeaseSet2 headers, datagrams and
+// private key files.
+func (o OfflineSignature) Bytes() []byte {
+	return append(o.signedBytes(), o.Signature...)
+}
+
+// ReadOfflineSignature decodes an offline signature block at the start of b.
+// destSigType is the signature type of the destination which signed it, and
+// determines the length of the signature. The bytes following the block are
+// returned.
+func ReadOfflineSignature(b []byte, destSigType SigType) (OfflineSignature, []byte, error) {
+	var o OfflineSignature
+	hdr, rest, err := readBytes(b, 6, "offline signature header")
+	if err != nil {
+		return o, nil, err
+	}
+	o.Expires = time.Unix(int64(binary.BigEndian.Uint32(hdr[0:4])), 0)
+	o.TransientType = SigType(binary.BigEndian.Uint16(hdr[4:6]))
+	if o.TransientType.PublicKeyLen() == 0 {
+		return o, nil, fmt.Errorf("unsupported transient signature type %s", o.TransientType)
+	}
+	if destSigType.SignatureLen() == 0 {
+		return o, nil, fmt.Errorf("unsupported signature type %s", destSigType)
+	}
+	o.TransientPublicKey, rest, err = readBytes(rest, o.TransientType.PublicKeyLen(), "transient public key")
+	if err != nil {
+		return o, nil, err
+	}
+	o.Signature, rest, err = readBytes(rest, destSigType.SignatureLen(), "offline signature")
+	if err != nil {
+		return o, nil, err
+	}
+	return o, rest, nil
+}
+
+// Expired reports whether the transient key is no longer valid at t.
+func (o OfflineSignature) Expired(t time.Time) bool {
+	return !t.Before(o.Expires)
+}
+
+// Verify checks that the block was signed by the destination addr and has
+// not expired.
+func (o OfflineSignature) Verify(addr I2PAddr) error {
+	if err := addr.VerifyMessage(o.signedBytes(), o.Signature); err != nil {
+		return fmt.Errorf("offline signature: %w", err)
+	}
+	if o.Expired(time.Now()) {
+		return fmt.Errorf("offline signature expired at %s", o.Expires.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// SignOffline signs a transient public key generated elsewhere, for instance
+// by a router, with the destination's long-term signing key. The keys must
+// hold the destination's signing key, not a transient one.
+func (k I2PKeys) SignOffline(transientType SigType, transientPublicKey []byte, expires time.Time) (OfflineSignature, error) {
+	log.WithField("type", transientType).WithField("expires", expires).Debug("Signing transient key")
+	p, err := k.privateKeyFile()
+	if err != nil {
+		return OfflineSignature{}, err
+	}
+	if p.offline != nil {
+		return OfflineSignature{}, errors.New("keys do not hold the destination's signing key")
+	}
+	if len(transientPublicKey) != transientType.PublicKeyLen() || transientType.PublicKeyLen() == 0 {
+		return OfflineSignature{}, fmt.Errorf("invalid %s public key length %d", transientType, len(transientPublicKey))
+	}
+	if expires.Unix() <= 0 || expires.Unix() > int64(^uint32(0)) {
+		return OfflineSignature{}, errors.New("expiration out of range")
+	}
+	o := OfflineSignature{
+		Expires:            time.Unix(expires.Unix(), 0),
+		TransientType:      transientType,
+		TransientPublicKey: append([]byte{}, transientPublicKey...),
+	}
+	o.Signature, err = signMessage(p.dest.sigType, p.signingPrivateKey, o.signedBytes())
+	if err != nil {
+		log.WithError(err).Error("Error signing transient key")
+		return OfflineSignature{}, err
+	}
+	return o, nil
+}
+
+// WithOfflineSignature returns keys for routers to use: the destination's
+// signing private key is removed and replaced by the transient private key
+// and its offline signature. Both the binary and base64 private key formats
+// carry the offline signature.
+func (k I2PKeys) WithOfflineSignature(o OfflineSignature, transientPrivateKey []byte) (I2PKeys, error) {
+	p, err := k.privateKeyFile()
+	if err != nil {
+		return I2PKeys{}, err
+	}
+	if len(transientPrivateKey) != o.TransientType.PrivateKeyLen() {
+		return I2PKeys{}, fmt.Errorf("invalid %s private key length %d", o.TransientType, len(transientPrivateKey))
+	}
+	if err := o.Verify(p.keys().Address); err != nil {
+		return I2PKeys{}, err
+	}
+	p.signingPrivateKey = make([]byte, p.dest.sigType.PrivateKeyLen())
+	p.offline = &offlineKeys{OfflineSignature: o, transientPrivateKey: append([]byte{}, transientPrivateKey...)}
+	return p.keys(), nil
+}
+
+// NewOfflineKeys generates a transient signing key of the given type, signs
+// it with the destination's signing key and returns keys holding only the
+// transient key. The original keys can then be kept in cold storage.
+func (k I2PKeys) NewOfflineKeys(transientType SigType, expires time.Time) (I2PKeys, error) {
+	pub, priv, err := GenerateSigningKey(transientType)
+	if err != nil {
+		return I2PKeys{}, err
+	}
+	defer wipe(priv)
+	o, err := k.SignOffline(transientType, pub, expires)
+	if err != nil {
+		return I2PKeys{}, err
+	}
+	return k.WithOfflineSignature(o, priv)
+}
+
+// OfflineSignature returns the offline signature carried by the keys, or nil
+// if the keys hold the destination's own signing key.
+func (k I2PKeys) OfflineSignature() (*OfflineSignature, error) {
+	p, err := k.privateKeyFile()
+	if err != nil {
+		return nil, err
+	}
+	if p.offline == nil {
+		return nil, nil
+	}
+	o := p.offline.OfflineSignature
+	return &o, nil
+}
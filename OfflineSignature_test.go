@@ -0,0 +1,103 @@
+package i2pkeys
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func Test_OfflineSignature(t *testing.T) {
+	keys, err := NewKeysFromSeed(bytes.Repeat([]byte{0x21}, SeedSize))
+	if err != nil {
+		t.Fatalf("NewKeysFromSeed failed: %v", err)
+	}
+	expires := time.Now().Add(24 * time.Hour)
+
+	t.Run("Create and verify", func(t *testing.T) {
+		offline, err := keys.NewOfflineKeys(SigTypeEd25519, expires)
+		if err != nil {
+			t.Fatalf("NewOfflineKeys failed: %v", err)
+		}
+		if offline.Addr() != keys.Addr() {
+			t.Error("Offline keys have a different address")
+		}
+		o, err := offline.OfflineSignature()
+		if err != nil || o == nil {
+			t.Fatalf("OfflineSignature failed: %v", err)
+		}
+		if o.Expires.Unix() != expires.Unix() {
+			t.Errorf("Wrong expiration %v", o.Expires)
+		}
+		if err := o.Verify(keys.Addr()); err != nil {
+			t.Errorf("Verify failed: %v", err)
+		}
+		other, _ := NewKeysFromSeed(bytes.Repeat([]byte{0x22}, SeedSize))
+		if err := o.Verify(other.Addr()); err == nil {
+			t.Error("Verify should have failed for another destination")
+		}
+
+		msg := []byte("signed by the transient key")
+		sig, err := offline.SignMessage(msg)
+		if err != nil {
+			t.Fatalf("SignMessage failed: %v", err)
+		}
+		if err := VerifySignature(o.TransientType, o.TransientPublicKey, msg, sig); err != nil {
+			t.Errorf("Transient signature does not verify: %v", err)
+		}
+		if _, err := offline.SignOffline(SigTypeEd25519, o.TransientPublicKey, expires); err == nil {
+			t.Error("SignOffline should have failed without the destination key")
+		}
+	})
+
+	t.Run("Stored in dat files", func(t *testing.T) {
+		offline, err := keys.NewOfflineKeys(SigTypeECDSASHA256P256, expires)
+		if err != nil {
+			t.Fatalf("NewOfflineKeys failed: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := StoreKeysDat(offline, &buf); err != nil {
+			t.Fatalf("StoreKeysDat failed: %v", err)
+		}
+		loaded, err := LoadKeysDat(&buf)
+		if err != nil {
+			t.Fatalf("LoadKeysDat failed: %v", err)
+		}
+		if loaded != offline {
+			t.Error("Offline keys did not round trip")
+		}
+		o, _ := loaded.OfflineSignature()
+		if o == nil || o.TransientType != SigTypeECDSASHA256P256 {
+			t.Error("Offline signature was lost")
+		}
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		pub, _, _ := GenerateSigningKey(SigTypeEd25519)
+		o, err := keys.SignOffline(SigTypeEd25519, pub, time.Now().Add(-time.Minute))
+		if err != nil {
+			t.Fatalf("SignOffline failed: %v", err)
+		}
+		if err := o.Verify(keys.Addr()); err == nil {
+			t.Error("Verify should have failed for expired signature")
+		}
+	})
+
+	t.Run("Tampered", func(t *testing.T) {
+		pub, _, _ := GenerateSigningKey(SigTypeEd25519)
+		o, err := keys.SignOffline(SigTypeEd25519, pub, expires)
+		if err != nil {
+			t.Fatalf("SignOffline failed: %v", err)
+		}
+		o.Expires = o.Expires.Add(time.Hour)
+		if err := o.Verify(keys.Addr()); err == nil {
+			t.Error("Verify should have failed for modified expiration")
+		}
+		parsed, rest, err := ReadOfflineSignature(append(o.Bytes(), 1, 2, 3), SigTypeEd25519)
+		if err != nil {
+			t.Fatalf("ReadOfflineSignature failed: %v", err)
+		}
+		if !bytes.Equal(rest, []byte{1, 2, 3}) || !bytes.Equal(parsed.Bytes(), o.Bytes()) {
+			t.Error("ReadOfflineSignature did not round trip")
+		}
+	})
+}
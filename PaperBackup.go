@@ -0,0 +1,221 @@
+package i2pkeys
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	paperHeader      = "I2P KEY BACKUP V1"
+	paperSumPrefix   = "SUM:"
+	paperGroupLen    = 4
+	paperGroups      = 8
+	paperLineLen     = paperGroupLen * paperGroups // 20 bytes of key per line
+	paperChecksumLen = 4
+
+	// maxPrivateKeyFileLen bounds a PrivateKeyFile: a destination with the
+	// largest certificate, ElGamal and RSA-4096 private keys, and an offline
+	// section with RSA-4096 transient keys.
+	maxPrivateKeyFileLen = minKeysAndCert + 0xffff + 256 + 1024 + 4 + 2 + 512 + 512 + 1024
+	paperMaxLines        = ((maxPrivateKeyFileLen*8+4)/5 + paperLineLen - 1) / paperLineLen
+)
+
+// PaperBackupError reports the lines of a paper backup which could not be
+// read back. Line numbers are the ones printed at the start of each line.
+type PaperBackupError struct {
+	Lines   []int  // numbered lines whose checksum failed or which are missing
+	Message string // problem which is not specific to a line, if any
+}
+
+func (e *PaperBackupError) Error() string {
+	if len(e.Lines) == 0 {
+		return "paper backup: " + e.Message
+	}
+	lines := make([]string, len(e.Lines))
+	for i, l := range e.Lines {
+		lines[i] = strconv.Itoa(l)
+	}
+	msg := "paper backup: check line(s) " + strings.Join(lines, ", ")
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+func paperLineChecksum(line int, data string) string {
+	sum := sha256.Sum256([]byte(strconv.Itoa(line) + ":" + data))
+	return i2pB32enc.EncodeToString(sum[:5])[:paperChecksumLen]
+}
+
+func paperChecksum(b []byte) string {
+	sum := sha256.Sum256(b)
+	return i2pB32enc.EncodeToString(sum[:5])
+}
+
+func groupString(s string, n int) string {
+	var groups []string
+	for len(s) > n {
+		groups = append(groups, s[:n])
+		s = s[n:]
+	}
+	return strings.Join(append(groups, s), " ")
+}
+
+// StoreKeysPaper writes keys in a format meant for printing and typing back
+// in by hand. The private keys are written in base32, in numbered lines of
+// short groups, each line ending with its own checksum. A final line holds the
+// number of lines and a checksum over the whole key:
+//
+//	I2P KEY BACKUP V1 <address>.b32.i2p
+//	01: abcd efgh ijkl mnop qrst uvwx yz23 4567  chk1
+//	...
+//	SUM: 23 abcd efgh
+func StoreKeysPaper(k I2PKeys, w io.Writer) error {
+	log.Debug("Storing paper backup")
+	p, err := k.privateKeyFile()
+	if err != nil {
+		log.WithError(err).Error("Error parsing keys")
+		return err
+	}
+	raw := p.bytes()
+	defer wipe(raw)
+	data := strings.TrimRight(i2pB32enc.EncodeToString(raw), "=")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n", paperHeader, I2PAddr(i2pB64enc.EncodeToString(p.dest.raw)).Base32())
+	line := 1
+	for ; len(data) > 0; line++ {
+		n := paperLineLen
+		if len(data) < n {
+			n = len(data)
+		}
+		chunk := data[:n]
+		data = data[n:]
+		fmt.Fprintf(&b, "%02d: %-*s  %s\n", line, paperLineLen+paperGroups-1, groupString(chunk, paperGroupLen), paperLineChecksum(line, chunk))
+	}
+	fmt.Fprintf(&b, "%s %d %s\n", paperSumPrefix, line-1, groupString(paperChecksum(raw), paperGroupLen))
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		log.WithError(err).Error("Error writing paper backup")
+		return fmt.Errorf("error writing keys: %w", err)
+	}
+	return nil
+}
+
+// LoadKeysPaper reads keys written by StoreKeysPaper. Case and whitespace do
+// not matter, so a backup may be typed back in loosely. If the backup does
+// not check out, the error is a *PaperBackupError naming the lines to check.
+func LoadKeysPaper(r io.Reader) (I2PKeys, error) {
+	log.Debug("Loading paper backup")
+	var (
+		address string
+		sum     string
+		count   int
+		bad     = make(map[int]bool)
+		chunks  = make(map[int]string)
+		last    int
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		text := strings.Join(strings.Fields(scanner.Text()), " ")
+		upper := strings.ToUpper(text)
+		switch {
+		case text == "":
+			continue
+		case strings.HasPrefix(upper, paperHeader):
+			address = strings.ToLower(strings.TrimSpace(text[len(paperHeader):]))
+			continue
+		case strings.HasPrefix(upper, paperSumPrefix):
+			fields := strings.Fields(text[len(paperSumPrefix):])
+			if len(fields) < 2 {
+				return I2PKeys{}, &PaperBackupError{Message: "malformed SUM line"}
+			}
+			n, err := strconv.Atoi(fields[0])
+			if err != nil || n < 1 || n > paperMaxLines {
+				return I2PKeys{}, &PaperBackupError{Message: "malformed line count on SUM line"}
+			}
+			count = n
+			sum = strings.ToLower(strings.Join(fields[1:], ""))
+			continue
+		}
+		colon := strings.Index(text, ":")
+		if colon < 0 {
+			return I2PKeys{}, &PaperBackupError{Message: fmt.Sprintf("unrecognized line %q", text)}
+		}
+		line, err := strconv.Atoi(strings.TrimSpace(text[:colon]))
+		if err != nil || line < 1 || line > paperMaxLines {
+			return I2PKeys{}, &PaperBackupError{Message: fmt.Sprintf("bad line number in %q", text)}
+		}
+		if _, ok := chunks[line]; ok || bad[line] {
+			return I2PKeys{}, &PaperBackupError{Lines: []int{line}, Message: "line number appears more than once"}
+		}
+		if line > last {
+			last = line
+		}
+		body := strings.ToLower(strings.Join(strings.Fields(text[colon+1:]), ""))
+		if len(body) <= paperChecksumLen {
+			bad[line] = true
+			continue
+		}
+		chunk, check := body[:len(body)-paperChecksumLen], body[len(body)-paperChecksumLen:]
+		if paperLineChecksum(line, chunk) != check {
+			bad[line] = true
+			continue
+		}
+		chunks[line] = chunk
+	}
+	if err := scanner.Err(); err != nil {
+		return I2PKeys{}, fmt.Errorf("error reading paper backup: %w", err)
+	}
+	if sum == "" {
+		return I2PKeys{}, &PaperBackupError{Message: "missing SUM line"}
+	}
+	if count > last {
+		last = count
+	} else if count < last {
+		return I2PKeys{}, &PaperBackupError{Message: fmt.Sprintf("SUM line says %d lines, found line %d", count, last)}
+	}
+	var lines []int
+	for line := 1; line <= last; line++ {
+		if _, ok := chunks[line]; !ok {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > 0 {
+		log.WithField("lines", lines).Error("Paper backup has bad lines")
+		return I2PKeys{}, &PaperBackupError{Lines: lines}
+	}
+	var data strings.Builder
+	for line := 1; line <= last; line++ {
+		chunk := chunks[line]
+		if line < last && len(chunk) != paperLineLen {
+			return I2PKeys{}, &PaperBackupError{Lines: []int{line}, Message: "line is too short"}
+		}
+		data.WriteString(chunk)
+	}
+	encoded := data.String()
+	if pad := len(encoded) % 8; pad != 0 {
+		encoded += strings.Repeat("=", 8-pad)
+	}
+	raw, err := i2pB32enc.DecodeString(encoded)
+	if err != nil {
+		return I2PKeys{}, &PaperBackupError{Lines: []int{last}, Message: "key data does not decode"}
+	}
+	defer wipe(raw)
+	if paperChecksum(raw) != sum {
+		return I2PKeys{}, &PaperBackupError{Message: "whole key checksum mismatch, check the SUM line"}
+	}
+	p, err := readPrivateKeyFile(raw)
+	if err != nil {
+		return I2PKeys{}, fmt.Errorf("paper backup does not contain valid keys: %w", err)
+	}
+	k := p.keys()
+	if address != "" && address != k.Address.Base32() {
+		return I2PKeys{}, &PaperBackupError{Message: "keys do not match the address in the header"}
+	}
+	log.WithField("addr", k.Address.Base32()).Debug("Loaded paper backup")
+	return k, nil
+}
@@ -0,0 +1,123 @@
+package i2pkeys
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_PaperBackup(t *testing.T) {
+	keys, err := NewKeysFromSeed(bytes.Repeat([]byte{0x09}, SeedSize))
+	if err != nil {
+		t.Fatalf("NewKeysFromSeed failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := StoreKeysPaper(keys, &buf); err != nil {
+		t.Fatalf("StoreKeysPaper failed: %v", err)
+	}
+	backup := buf.String()
+	lines := strings.Split(strings.TrimSpace(backup), "\n")
+
+	t.Run("Round trip", func(t *testing.T) {
+		loaded, err := LoadKeysPaper(strings.NewReader(backup))
+		if err != nil {
+			t.Fatalf("LoadKeysPaper failed: %v", err)
+		}
+		if loaded != keys {
+			t.Error("LoadKeysPaper returned different keys")
+		}
+	})
+
+	t.Run("Elgamal keys", func(t *testing.T) {
+		keys := testPrivateKeyFile(t, CryptoTypeElGamal, SigTypeEd25519).keys()
+		var buf bytes.Buffer
+		if err := StoreKeysPaper(keys, &buf); err != nil {
+			t.Fatalf("StoreKeysPaper failed: %v", err)
+		}
+		loaded, err := LoadKeysPaper(&buf)
+		if err != nil {
+			t.Fatalf("LoadKeysPaper failed: %v", err)
+		}
+		if loaded != keys {
+			t.Error("LoadKeysPaper returned different keys")
+		}
+	})
+
+	t.Run("Case and whitespace", func(t *testing.T) {
+		sloppy := strings.ToUpper(strings.ReplaceAll(backup, " ", "   "))
+		sloppy = strings.ReplaceAll(sloppy, "\n", "\n\n ")
+		loaded, err := LoadKeysPaper(strings.NewReader(sloppy))
+		if err != nil {
+			t.Fatalf("LoadKeysPaper failed: %v", err)
+		}
+		if loaded != keys {
+			t.Error("LoadKeysPaper returned different keys")
+		}
+	})
+
+	t.Run("Typo is pinpointed", func(t *testing.T) {
+		typo := append([]string{}, lines...)
+		line := []byte(typo[5])
+		if line[6] == 'a' {
+			line[6] = 'b'
+		} else {
+			line[6] = 'a'
+		}
+		typo[5] = string(line)
+		_, err := LoadKeysPaper(strings.NewReader(strings.Join(typo, "\n")))
+		var perr *PaperBackupError
+		if !errors.As(err, &perr) {
+			t.Fatalf("Expected PaperBackupError, got %v", err)
+		}
+		if len(perr.Lines) != 1 || perr.Lines[0] != 5 {
+			t.Errorf("Expected line 5 to be reported, got %v", perr.Lines)
+		}
+	})
+
+	t.Run("Missing lines", func(t *testing.T) {
+		missing := append(append([]string{}, lines[:3]...), lines[4:len(lines)-2]...)
+		missing = append(missing, lines[len(lines)-1])
+		_, err := LoadKeysPaper(strings.NewReader(strings.Join(missing, "\n")))
+		var perr *PaperBackupError
+		if !errors.As(err, &perr) {
+			t.Fatalf("Expected PaperBackupError, got %v", err)
+		}
+		last := len(lines) - 2
+		if len(perr.Lines) != 2 || perr.Lines[0] != 3 || perr.Lines[1] != last {
+			t.Errorf("Expected lines 3 and %d to be reported, got %v", last, perr.Lines)
+		}
+	})
+
+	t.Run("Swapped lines", func(t *testing.T) {
+		swapped := append([]string{}, lines...)
+		swapped[1], swapped[2] = "01"+swapped[2][2:], "02"+swapped[1][2:]
+		if _, err := LoadKeysPaper(strings.NewReader(strings.Join(swapped, "\n"))); err == nil {
+			t.Error("LoadKeysPaper should have failed for swapped lines")
+		}
+	})
+	t.Run("Huge line count", func(t *testing.T) {
+		huge := append([]string{}, lines...)
+		sum := strings.Fields(huge[len(huge)-1])
+		huge[len(huge)-1] = strings.Join(append([]string{sum[0], "999999999"}, sum[2:]...), " ")
+		if _, err := LoadKeysPaper(strings.NewReader(strings.Join(huge, "\n"))); err == nil {
+			t.Error("LoadKeysPaper should have failed for a huge SUM count")
+		}
+		huge = append(append([]string{}, lines...), "999999999: aaaa bbbb")
+		if _, err := LoadKeysPaper(strings.NewReader(strings.Join(huge, "\n"))); err == nil {
+			t.Error("LoadKeysPaper should have failed for a huge line number")
+		}
+	})
+
+	t.Run("Duplicate lines", func(t *testing.T) {
+		dup := append(append([]string{}, lines[:3]...), lines[2:]...)
+		_, err := LoadKeysPaper(strings.NewReader(strings.Join(dup, "\n")))
+		var perr *PaperBackupError
+		if !errors.As(err, &perr) {
+			t.Fatalf("Expected PaperBackupError, got %v", err)
+		}
+		if len(perr.Lines) != 1 || perr.Lines[0] != 2 {
+			t.Errorf("Expected line 2 to be reported, got %v", perr.Lines)
+		}
+	})
+}
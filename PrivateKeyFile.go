@@ -0,0 +1,197 @@
+package i2pkeys
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// offlineKeys is the optional trailer of a private key file whose
+// destination signing key is kept offline. The destination's signing private
+// key is all zeros in that case and the transient key is used in its place.
+type offlineKeys struct {
+	OfflineSignature
+	transientPrivateKey []byte
+}
+
+// privateKeyFile is the decoded form of the PrivateKeyFile format used by
+// Java I2P and i2pd .dat files, and, base64 encoded, by SAM and I2PKeys.Both.
+type privateKeyFile struct {
+	dest              keysAndCert
+	privateKey        []byte
+	signingPrivateKey []byte
+	offline           *offlineKeys
+}
+
+func readBytes(b []byte, n int, what string) ([]byte, []byte, error) {
+	if len(b) < n {
+		return nil, nil, fmt.Errorf("%s truncated: need %d bytes, have %d", what, n, len(b))
+	}
+	return b[:n], b[n:], nil
+}
+
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// readPrivateKeyFile decodes a complete private key file.
+func readPrivateKeyFile(b []byte) (p privateKeyFile, err error) {
+	p.dest, err = readKeysAndCert(b)
+	if err != nil {
+		return p, fmt.Errorf("error reading destination: %w", err)
+	}
+	rest := b[len(p.dest.raw):]
+	p.privateKey, rest, err = readBytes(rest, p.dest.cryptoType.PrivateKeyLen(), "private key")
+	if err != nil {
+		return p, err
+	}
+	p.signingPrivateKey, rest, err = readBytes(rest, p.dest.sigType.PrivateKeyLen(), "signing private key")
+	if err != nil {
+		return p, err
+	}
+	if isZero(p.signingPrivateKey) && len(rest) > 0 {
+		off := &offlineKeys{}
+		off.OfflineSignature, rest, err = ReadOfflineSignature(rest, p.dest.sigType)
+		if err != nil {
+			return p, err
+		}
+		off.transientPrivateKey, rest, err = readBytes(rest, off.TransientType.PrivateKeyLen(), "transient private key")
+		if err != nil {
+			return p, err
+		}
+		p.offline = off
+	}
+	if len(rest) != 0 {
+		return p, fmt.Errorf("trailing data after private keys: %d bytes", len(rest))
+	}
+	return p, nil
+}
+
+// bytes serializes the private key file.
+func (p privateKeyFile) bytes() []byte {
+	var buf bytes.Buffer
+	buf.Write(p.dest.raw)
+	buf.Write(p.privateKey)
+	buf.Write(p.signingPrivateKey)
+	if p.offline != nil {
+		buf.Write(p.offline.Bytes())
+		buf.Write(p.offline.transientPrivateKey)
+	}
+	return buf.Bytes()
+}
+
+// keys returns the I2PKeys holding this private key file.
+func (p privateKeyFile) keys() I2PKeys {
+	return I2PKeys{
+		Address: I2PAddr(i2pB64enc.EncodeToString(p.dest.raw)),
+		Both:    i2pB64enc.EncodeToString(p.bytes()),
+	}
+}
+
+// privateKeyFile decodes the Both field of the keys. Both is the base64
+// PrivateKeyFile returned by SAM as PRIV, which NewDestination and older
+// key files prefix with the base64 destination; that form is accepted too.
+func (k I2PKeys) privateKeyFile() (privateKeyFile, error) {
+	both, pub := strings.TrimSpace(k.Both), strings.TrimSpace(string(k.Address))
+	p, err := k.decodePrivateKeyFile(both)
+	if err != nil && pub != "" && strings.HasPrefix(both, pub) {
+		if q, err := k.decodePrivateKeyFile(strings.TrimSpace(both[len(pub):])); err == nil {
+			return q, nil
+		}
+	}
+	return p, err
+}
+
+// decodePrivateKeyFile decodes a base64 PrivateKeyFile and checks it against
+// the address of the keys.
+func (k I2PKeys) decodePrivateKeyFile(both string) (privateKeyFile, error) {
+	b, err := i2pB64enc.DecodeString(both)
+	if err != nil {
+		return privateKeyFile{}, fmt.Errorf("error decoding private keys: %w", err)
+	}
+	p, err := readPrivateKeyFile(b)
+	if err != nil {
+		return p, err
+	}
+	if pub := strings.TrimSpace(string(k.Address)); pub != "" {
+		addr, err := I2PAddr(pub).ToBytes()
+		if err != nil {
+			return p, fmt.Errorf("error decoding address: %w", err)
+		}
+		if !bytes.Equal(addr, p.dest.raw) {
+			return p, errors.New("address does not match destination in private keys")
+		}
+	}
+	return p, nil
+}
+
+// LoadKeysDat loads keys from the binary PrivateKeyFile format used by Java
+// I2P's i2ptunnel and by i2pd, including keys with offline signatures.
+func LoadKeysDat(r io.Reader) (I2PKeys, error) {
+	log.Debug("Loading binary keys from reader")
+	b, err := io.ReadAll(r)
+	if err != nil {
+		log.WithError(err).Error("Error reading binary keys")
+		return I2PKeys{}, fmt.Errorf("error reading keys: %w", err)
+	}
+	p, err := readPrivateKeyFile(b)
+	if err != nil {
+		log.WithError(err).Error("Error parsing binary keys")
+		return I2PKeys{}, fmt.Errorf("invalid private key file: %w", err)
+	}
+	k := p.keys()
+	log.WithField("addr", k.Address.Base32()).Debug("Loaded binary keys")
+	return k, nil
+}
+
+// StoreKeysDat writes keys in the binary PrivateKeyFile format used by Java
+// I2P's i2ptunnel and by i2pd.
+func StoreKeysDat(k I2PKeys, w io.Writer) error {
+	log.Debug("Storing binary keys")
+	p, err := k.privateKeyFile()
+	if err != nil {
+		log.WithError(err).Error("Error parsing keys")
+		return err
+	}
+	if _, err := w.Write(p.bytes()); err != nil {
+		log.WithError(err).Error("Error writing binary keys")
+		return fmt.Errorf("error writing keys: %w", err)
+	}
+	log.WithField("addr", k.Address.Base32()).Debug("Binary keys stored successfully")
+	return nil
+}
+
+// LoadKeysDatFile loads keys from a binary .dat private key file.
+func LoadKeysDatFile(filename string) (I2PKeys, error) {
+	log.WithField("filename", filename).Debug("Loading binary keys from file")
+	fi, err := os.Open(filename)
+	if err != nil {
+		log.WithError(err).WithField("filename", filename).Error("Error opening file")
+		return I2PKeys{}, fmt.Errorf("error opening file: %w", err)
+	}
+	defer fi.Close()
+	return LoadKeysDat(fi)
+}
+
+// StoreKeysDatFile writes keys to a binary .dat private key file, replacing
+// the file if it exists. The file is only readable by its owner.
+func StoreKeysDatFile(k I2PKeys, filename string) error {
+	log.WithField("filename", filename).Debug("Storing binary keys to file")
+	var buf bytes.Buffer
+	if err := StoreKeysDat(k, &buf); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filename, buf.Bytes(), 0600); err != nil {
+		log.WithError(err).WithField("filename", filename).Error("Error writing file")
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package i2pkeys
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func randomBytes(t *testing.T, n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatalf("Failed to read random bytes: %v", err)
+	}
+	return b
+}
+
+// testPrivateKeyFile builds a private key file with random key material of
+// the given types.
+func testPrivateKeyFile(t *testing.T, cryptoType CryptoType, sigType SigType) privateKeyFile {
+	raw, err := newKeysAndCert(cryptoType, randomBytes(t, cryptoType.PublicKeyLen()), sigType, randomBytes(t, sigType.PublicKeyLen()), randomBytes(t, 32))
+	if err != nil {
+		t.Fatalf("newKeysAndCert failed: %v", err)
+	}
+	dest, err := readKeysAndCert(raw)
+	if err != nil {
+		t.Fatalf("readKeysAndCert failed: %v", err)
+	}
+	return privateKeyFile{
+		dest:              dest,
+		privateKey:        randomBytes(t, cryptoType.PrivateKeyLen()),
+		signingPrivateKey: randomBytes(t, sigType.PrivateKeyLen()),
+	}
+}
+
+func Test_KeysAndCert(t *testing.T) {
+	t.Run("Existing destination", func(t *testing.T) {
+		addr := I2PAddr(validI2PAddrB64)
+		sigType, err := addr.SigType()
+		if err != nil {
+			t.Fatalf("SigType failed: %v", err)
+		}
+		if sigType != SigTypeEd25519 {
+			t.Errorf("Wrong signature type. Got %s, want %s", sigType, SigTypeEd25519)
+		}
+		cryptoType, err := addr.CryptoType()
+		if err != nil {
+			t.Fatalf("CryptoType failed: %v", err)
+		}
+		if cryptoType != CryptoTypeElGamal {
+			t.Errorf("Wrong encryption type. Got %s, want %s", cryptoType, CryptoTypeElGamal)
+		}
+	})
+
+	t.Run("Oversized signing key", func(t *testing.T) {
+		p := testPrivateKeyFile(t, CryptoTypeElGamal, SigTypeECDSASHA512P521)
+		if len(p.dest.raw) != minKeysAndCert+keyCertMinBytes+4 {
+			t.Errorf("Wrong destination length %d", len(p.dest.raw))
+		}
+		addr := p.keys().Address
+		key, err := addr.SigningPublicKey()
+		if err != nil {
+			t.Fatalf("SigningPublicKey failed: %v", err)
+		}
+		if !bytes.Equal(key, p.dest.signingKey) {
+			t.Error("Signing key was not reassembled from the key certificate")
+		}
+	})
+
+	t.Run("NULL certificate", func(t *testing.T) {
+		p := testPrivateKeyFile(t, CryptoTypeElGamal, SigTypeDSASHA1)
+		if p.dest.certType != certTypeNull || len(p.dest.raw) != minKeysAndCert {
+			t.Errorf("DSA/ElGamal destination should have a NULL certificate")
+		}
+	})
+}
+
+func Test_KeysDat(t *testing.T) {
+	t.Run("Round trip", func(t *testing.T) {
+		p := testPrivateKeyFile(t, CryptoTypeX25519, SigTypeEd25519)
+		loaded, err := LoadKeysDat(bytes.NewReader(p.bytes()))
+		if err != nil {
+			t.Fatalf("LoadKeysDat failed: %v", err)
+		}
+		if loaded != p.keys() {
+			t.Errorf("LoadKeysDat returned different keys")
+		}
+		var buf bytes.Buffer
+		if err := StoreKeysDat(loaded, &buf); err != nil {
+			t.Fatalf("StoreKeysDat failed: %v", err)
+		}
+		if !bytes.Equal(buf.Bytes(), p.bytes()) {
+			t.Error("StoreKeysDat did not reproduce the original file")
+		}
+	})
+
+	t.Run("Offline signature", func(t *testing.T) {
+		p := testPrivateKeyFile(t, CryptoTypeX25519, SigTypeEd25519)
+		p.signingPrivateKey = make([]byte, SigTypeEd25519.PrivateKeyLen())
+		p.offline = &offlineKeys{
+			OfflineSignature: OfflineSignature{
+				Expires:            time.Unix(1700000000, 0),
+				TransientType:      SigTypeECDSASHA256P256,
+				TransientPublicKey: randomBytes(t, SigTypeECDSASHA256P256.PublicKeyLen()),
+				Signature:          randomBytes(t, SigTypeEd25519.SignatureLen()),
+			},
+			transientPrivateKey: randomBytes(t, SigTypeECDSASHA256P256.PrivateKeyLen()),
+		}
+		raw := p.bytes()
+		parsed, err := readPrivateKeyFile(raw)
+		if err != nil {
+			t.Fatalf("readPrivateKeyFile failed: %v", err)
+		}
+		if parsed.offline == nil {
+			t.Fatal("Offline section was not parsed")
+		}
+		if parsed.offline.Expires.Unix() != 1700000000 || parsed.offline.TransientType != SigTypeECDSASHA256P256 {
+			t.Errorf("Offline section header mismatch: %+v", parsed.offline)
+		}
+		if !bytes.Equal(parsed.bytes(), raw) {
+			t.Error("Offline section did not round trip")
+		}
+	})
+
+	t.Run("SAM destination form", func(t *testing.T) {
+		// NewDestination stores the PUB and PRIV replies of DEST GENERATE,
+		// PUB with its trailing space, concatenated in Both.
+		for _, sigType := range []SigType{SigTypeEd25519, SigTypeDSASHA1} {
+			p := testPrivateKeyFile(t, CryptoTypeElGamal, sigType)
+			canonical := p.keys()
+			pub := string(canonical.Address) + " "
+			keys := I2PKeys{Address: I2PAddr(pub), Both: pub + canonical.Both}
+			var buf bytes.Buffer
+			if err := StoreKeysDat(keys, &buf); err != nil {
+				t.Fatalf("StoreKeysDat failed for %s: %v", sigType, err)
+			}
+			if !bytes.Equal(buf.Bytes(), p.bytes()) {
+				t.Errorf("StoreKeysDat wrote different keys for %s", sigType)
+			}
+		}
+	})
+
+	t.Run("Truncated file", func(t *testing.T) {
+		raw := testPrivateKeyFile(t, CryptoTypeElGamal, SigTypeEd25519).bytes()
+		if _, err := LoadKeysDat(bytes.NewReader(raw[:len(raw)-1])); err == nil {
+			t.Error("LoadKeysDat should have failed for truncated file")
+		}
+	})
+
+	t.Run("Unknown signature type", func(t *testing.T) {
+		raw := testPrivateKeyFile(t, CryptoTypeElGamal, SigTypeEd25519).bytes()
+		binary.BigEndian.PutUint16(raw[minKeysAndCert:], 0xffff)
+		if _, err := LoadKeysDat(bytes.NewReader(raw)); err == nil {
+			t.Error("LoadKeysDat should have failed for unknown signature type")
+		}
+	})
+
+	t.Run("Mismatched address", func(t *testing.T) {
+		k := testPrivateKeyFile(t, CryptoTypeElGamal, SigTypeEd25519).keys()
+		k.Address = testPrivateKeyFile(t, CryptoTypeElGamal, SigTypeEd25519).keys().Address
+		if err := StoreKeysDat(k, &bytes.Buffer{}); err == nil {
+			t.Error("StoreKeysDat should have failed for mismatched address")
+		}
+	})
+
+	t.Run("File", func(t *testing.T) {
+		tmpDir, err := os.MkdirTemp("", "test_keys_")
+		if err != nil {
+			t.Fatalf("Failed to create temp directory: %v", err)
+		}
+		defer os.RemoveAll(tmpDir)
+		tmpFilePath := filepath.Join(tmpDir, "test_keys.dat")
+
+		keys := testPrivateKeyFile(t, CryptoTypeElGamal, SigTypeEd25519).keys()
+		if err := StoreKeysDatFile(keys, tmpFilePath); err != nil {
+			t.Fatalf("StoreKeysDatFile failed: %v", err)
+		}
+		loaded, err := LoadKeysDatFile(tmpFilePath)
+		if err != nil {
+			t.Fatalf("LoadKeysDatFile failed: %v", err)
+		}
+		if loaded != keys {
+			t.Error("Loaded keys do not match original")
+		}
+	})
+}
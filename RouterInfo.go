@@ -0,0 +1,202 @@
+package i2pkeys
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RouterAddress is a transport address published in a RouterInfo.
+type RouterAddress struct {
+	Cost       byte
+	Expiration time.Time // zero, routers do not set it
+	Transport  string    // "NTCP2" or "SSU2"
+	Options    map[string]string
+}
+
+// RouterInfo is a decoded RouterInfo, as stored in a router's netDb
+// directory.
+type RouterInfo struct {
+	Identity  I2PAddr // the RouterIdentity, which has the layout of a destination
+	Hash      RouterHash
+	Published time.Time
+	Addresses []RouterAddress
+	Peers     []RouterHash // unused, always empty
+	Options   map[string]string
+	Signature []byte
+
+	signed []byte // data covered by Signature
+}
+
+// readDate decodes an I2P Date, milliseconds since the epoch with 0 meaning
+// unset.
+func readDate(b []byte, what string) (time.Time, []byte, error) {
+	d, rest, err := readBytes(b, 8, what)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	ms := binary.BigEndian.Uint64(d)
+	if ms == 0 {
+		return time.Time{}, rest, nil
+	}
+	return time.UnixMilli(int64(ms)), rest, nil
+}
+
+// ReadRouterInfo decodes a RouterInfo. It does not check the signature, see
+// Verify.
+func ReadRouterInfo(b []byte) (*RouterInfo, error) {
+	ri, err := readRouterInfo(b)
+	if err != nil {
+		log.WithError(err).Error("Error reading RouterInfo")
+		return nil, fmt.Errorf("invalid RouterInfo: %w", err)
+	}
+	log.WithField("router", ri.Hash.String()).Debug("Read RouterInfo")
+	return ri, nil
+}
+
+func readRouterInfo(b []byte) (*RouterInfo, error) {
+	kc, err := readKeysAndCert(b)
+	if err != nil {
+		return nil, fmt.Errorf("error reading router identity: %w", err)
+	}
+	ri := &RouterInfo{
+		Identity: I2PAddr(i2pB64enc.EncodeToString(kc.raw)),
+		Hash:     RouterHash(sha256.Sum256(kc.raw)),
+	}
+	rest := b[len(kc.raw):]
+	if ri.Published, rest, err = readDate(rest, "published date"); err != nil {
+		return nil, err
+	}
+	num, rest, err := readBytes(rest, 1, "address count")
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < int(num[0]); i++ {
+		var (
+			a    RouterAddress
+			cost []byte
+		)
+		if cost, rest, err = readBytes(rest, 1, "address cost"); err != nil {
+			return nil, err
+		}
+		a.Cost = cost[0]
+		if a.Expiration, rest, err = readDate(rest, "address expiration"); err != nil {
+			return nil, err
+		}
+		if a.Transport, rest, err = readString(rest); err != nil {
+			return nil, err
+		}
+		if a.Options, rest, err = readMapping(rest); err != nil {
+			return nil, fmt.Errorf("%s address: %w", a.Transport, err)
+		}
+		ri.Addresses = append(ri.Addresses, a)
+	}
+	if num, rest, err = readBytes(rest, 1, "peer count"); err != nil {
+		return nil, err
+	}
+	for i := 0; i < int(num[0]); i++ {
+		var h []byte
+		if h, rest, err = readBytes(rest, 32, "peer"); err != nil {
+			return nil, err
+		}
+		ri.Peers = append(ri.Peers, RouterHash(h))
+	}
+	if ri.Options, rest, err = readMapping(rest); err != nil {
+		return nil, err
+	}
+	if kc.sigType.SignatureLen() == 0 {
+		return nil, fmt.Errorf("%w %s", ErrUnsupportedSigType, kc.sigType)
+	}
+	if len(rest) != kc.sigType.SignatureLen() {
+		return nil, fmt.Errorf("signature length %d, want %d", len(rest), kc.sigType.SignatureLen())
+	}
+	ri.Signature = rest
+	ri.signed = b[:len(b)-len(rest)]
+	return ri, nil
+}
+
+// Verify checks the signature of the RouterInfo with the router identity's
+// signing key.
+func (ri *RouterInfo) Verify() error {
+	if len(ri.signed) == 0 {
+		return errors.New("RouterInfo was not read with ReadRouterInfo")
+	}
+	if err := ri.Identity.VerifyMessage(ri.signed, ri.Signature); err != nil {
+		return fmt.Errorf("RouterInfo signature: %w", err)
+	}
+	return nil
+}
+
+// Caps returns the capability letters the router publishes, such as "XfR".
+func (ri *RouterInfo) Caps() string {
+	return ri.Options["caps"]
+}
+
+// LoadRouterInfoFile reads a RouterInfo from a netDb file.
+func LoadRouterInfoFile(path string) (*RouterInfo, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading RouterInfo: %w", err)
+	}
+	ri, err := ReadRouterInfo(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return ri, nil
+}
+
+// routerInfoFileHash returns the router hash encoded in a netDb file name of
+// the form routerInfo-<base64 hash>.dat.
+func routerInfoFileHash(name string) (RouterHash, bool) {
+	if !strings.HasPrefix(name, "routerInfo-") || !strings.HasSuffix(name, ".dat") {
+		return RouterHash{}, false
+	}
+	b, err := i2pB64enc.DecodeString(strings.TrimSuffix(strings.TrimPrefix(name, "routerInfo-"), ".dat"))
+	if err != nil || len(b) != 32 {
+		return RouterHash{}, false
+	}
+	return RouterHash(b), true
+}
+
+// WalkNetDb calls fn for each RouterInfo file in the netDb directory dir of
+// a Java I2P or i2pd router, with the router hash taken from the file name.
+// Other files are skipped.
+func WalkNetDb(dir string, fn func(hash RouterHash, path string) error) error {
+	log.WithField("dir", dir).Debug("Walking netDb")
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		hash, ok := routerInfoFileHash(d.Name())
+		if !ok {
+			log.WithField("path", path).Debug("Skipping file in netDb")
+			return nil
+		}
+		return fn(hash, path)
+	})
+}
+
+// NetDbRouterHashes returns the hashes of all routers in the netDb directory
+// dir, sorted by their base64 form.
+func NetDbRouterHashes(dir string) ([]RouterHash, error) {
+	var hashes []RouterHash
+	err := WalkNetDb(dir, func(hash RouterHash, _ string) error {
+		hashes = append(hashes, hash)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading netDb: %w", err)
+	}
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i].String() < hashes[j].String() })
+	return hashes, nil
+}
@@ -0,0 +1,114 @@
+package i2pkeys
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testRouterInfo builds a signed RouterInfo for the identity of keys.
+func testRouterInfo(t *testing.T, keys I2PKeys, published time.Time) []byte {
+	b, _ := keys.Addr().ToBytes()
+	b = binary.BigEndian.AppendUint64(b, uint64(published.UnixMilli()))
+	b = append(b, 1, 10)
+	b = binary.BigEndian.AppendUint64(b, 0)
+	b = append(b, 5)
+	b = append(b, "NTCP2"...)
+	b, err := appendMapping(b, map[string]string{"host": "192.0.2.1", "port": "12345", "v": "2"})
+	if err != nil {
+		t.Fatalf("appendMapping failed: %v", err)
+	}
+	b = append(b, 0)
+	if b, err = appendMapping(b, map[string]string{"caps": "XfR", "netId": "2", "router.version": "0.9.67"}); err != nil {
+		t.Fatalf("appendMapping failed: %v", err)
+	}
+	sig, err := keys.SignMessage(b)
+	if err != nil {
+		t.Fatalf("SignMessage failed: %v", err)
+	}
+	return append(b, sig...)
+}
+
+func Test_RouterInfo(t *testing.T) {
+	keys, err := GenerateDestination(SigTypeEd25519, CryptoTypeX25519)
+	if err != nil {
+		t.Fatalf("GenerateDestination failed: %v", err)
+	}
+	published := time.UnixMilli(time.Now().UnixMilli())
+	b := testRouterInfo(t, keys, published)
+
+	t.Run("Parse and verify", func(t *testing.T) {
+		ri, err := ReadRouterInfo(b)
+		if err != nil {
+			t.Fatalf("ReadRouterInfo failed: %v", err)
+		}
+		if ri.Identity != keys.Addr() || ri.Hash != RouterHash(keys.Addr().DestHash()) {
+			t.Error("Router identity or hash does not match")
+		}
+		if !ri.Published.Equal(published) || ri.Caps() != "XfR" || len(ri.Peers) != 0 {
+			t.Errorf("Unexpected RouterInfo %+v", ri)
+		}
+		if len(ri.Addresses) != 1 || ri.Addresses[0].Transport != "NTCP2" || ri.Addresses[0].Cost != 10 ||
+			ri.Addresses[0].Options["port"] != "12345" || !ri.Addresses[0].Expiration.IsZero() {
+			t.Errorf("Unexpected addresses %+v", ri.Addresses)
+		}
+		if err := ri.Verify(); err != nil {
+			t.Errorf("Verify failed: %v", err)
+		}
+	})
+
+	t.Run("Tampered", func(t *testing.T) {
+		bad := append([]byte{}, b...)
+		bad[len(bad)-70] ^= 1
+		ri, err := ReadRouterInfo(bad)
+		if err == nil && ri.Verify() == nil {
+			t.Error("Verify should have failed for a modified RouterInfo")
+		}
+		if _, err := ReadRouterInfo(b[:len(b)-1]); err == nil {
+			t.Error("ReadRouterInfo should have failed for a truncated RouterInfo")
+		}
+	})
+
+	t.Run("netDb directory", func(t *testing.T) {
+		dir := t.TempDir()
+		other, _ := GenerateDestination(SigTypeEd25519, CryptoTypeX25519)
+		want := map[RouterHash]bool{}
+		for _, k := range []I2PKeys{keys, other} {
+			hash := RouterHash(k.Addr().DestHash())
+			want[hash] = true
+			sub := filepath.Join(dir, "r"+hash.String()[:1])
+			if err := os.MkdirAll(sub, 0o700); err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(sub, "routerInfo-"+hash.String()+".dat")
+			if err := os.WriteFile(path, testRouterInfo(t, k, published), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := os.WriteFile(filepath.Join(dir, "README"), []byte("not a router"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		hashes, err := NetDbRouterHashes(dir)
+		if err != nil {
+			t.Fatalf("NetDbRouterHashes failed: %v", err)
+		}
+		if len(hashes) != 2 || !want[hashes[0]] || !want[hashes[1]] || hashes[0].String() > hashes[1].String() {
+			t.Errorf("Unexpected hashes %v", hashes)
+		}
+		err = WalkNetDb(dir, func(hash RouterHash, path string) error {
+			ri, err := LoadRouterInfoFile(path)
+			if err != nil {
+				return err
+			}
+			if ri.Hash != hash {
+				t.Errorf("%s: hash %s does not match file name", path, ri.Hash)
+			}
+			return ri.Verify()
+		})
+		if err != nil {
+			t.Errorf("WalkNetDb failed: %v", err)
+		}
+	})
+}
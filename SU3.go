@@ -0,0 +1,304 @@
+package i2pkeys
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SU3FileType is the format of the content of an su3 file.
+type SU3FileType byte
+
+const (
+	SU3FileTypeZip   SU3FileType = 0
+	SU3FileTypeXML   SU3FileType = 1
+	SU3FileTypeHTML  SU3FileType = 2
+	SU3FileTypeXMLGZ SU3FileType = 3
+	SU3FileTypeTXTGZ SU3FileType = 4
+	SU3FileTypeDMG   SU3FileType = 5
+	SU3FileTypeEXE   SU3FileType = 6
+)
+
+func (t SU3FileType) String() string {
+	switch t {
+	case SU3FileTypeZip:
+		return "zip"
+	case SU3FileTypeXML:
+		return "xml"
+	case SU3FileTypeHTML:
+		return "html"
+	case SU3FileTypeXMLGZ:
+		return "xml.gz"
+	case SU3FileTypeTXTGZ:
+		return "txt.gz"
+	case SU3FileTypeDMG:
+		return "dmg"
+	case SU3FileTypeEXE:
+		return "exe"
+	}
+	return "SU3FileType(" + strconv.Itoa(int(t)) + ")"
+}
+
+// SU3ContentType is what an su3 file is used for.
+type SU3ContentType byte
+
+const (
+	SU3ContentTypeUnknown      SU3ContentType = 0
+	SU3ContentTypeRouterUpdate SU3ContentType = 1
+	SU3ContentTypePlugin       SU3ContentType = 2
+	SU3ContentTypeReseed       SU3ContentType = 3
+	SU3ContentTypeNews         SU3ContentType = 4
+	SU3ContentTypeBlocklist    SU3ContentType = 5
+)
+
+func (t SU3ContentType) String() string {
+	switch t {
+	case SU3ContentTypeUnknown:
+		return "unknown"
+	case SU3ContentTypeRouterUpdate:
+		return "router update"
+	case SU3ContentTypePlugin:
+		return "plugin"
+	case SU3ContentTypeReseed:
+		return "reseed"
+	case SU3ContentTypeNews:
+		return "news"
+	case SU3ContentTypeBlocklist:
+		return "blocklist"
+	}
+	return "SU3ContentType(" + strconv.Itoa(int(t)) + ")"
+}
+
+const (
+	su3Magic         = "I2Psu3"
+	su3HeaderLen     = 40
+	su3MinVersionLen = 16
+)
+
+// SU3File is a signed file as used for reseed bundles, router updates,
+// plugins and news feeds. The signature covers the header and the content.
+type SU3File struct {
+	SigType     SigType
+	Version     string
+	SignerID    string // e.g. "zzz@mail.i2p"
+	FileType    SU3FileType
+	ContentType SU3ContentType
+	Content     []byte
+	Signature   []byte
+
+	signed []byte // the header and content, as covered by the signature
+}
+
+// ReadSU3 parses an su3 file. It does not check the signature, see Verify.
+func ReadSU3(b []byte) (*SU3File, error) {
+	log.WithField("length", len(b)).Debug("Reading su3 file")
+	if len(b) < su3HeaderLen {
+		return nil, fmt.Errorf("su3 file too short: %d bytes", len(b))
+	}
+	if string(b[:6]) != su3Magic {
+		return nil, errors.New("not an su3 file")
+	}
+	if b[7] != 0 {
+		return nil, fmt.Errorf("unsupported su3 format version %d", b[7])
+	}
+	f := &SU3File{
+		SigType:     SigType(binary.BigEndian.Uint16(b[8:10])),
+		FileType:    SU3FileType(b[25]),
+		ContentType: SU3ContentType(b[27]),
+	}
+	sigLen := int(binary.BigEndian.Uint16(b[10:12]))
+	if n := f.SigType.SignatureLen(); n != 0 && n != sigLen {
+		return nil, fmt.Errorf("invalid %s signature length %d", f.SigType, sigLen)
+	}
+	versionLen, signerLen := int(b[13]), int(b[15])
+	if versionLen < su3MinVersionLen {
+		return nil, fmt.Errorf("su3 version field too short: %d bytes", versionLen)
+	}
+	contentLen := binary.BigEndian.Uint64(b[16:24])
+	rest := uint64(len(b) - su3HeaderLen - versionLen - signerLen - sigLen)
+	if len(b) < su3HeaderLen+versionLen+signerLen+sigLen || contentLen != rest {
+		return nil, fmt.Errorf("su3 file length %d does not match its header", len(b))
+	}
+	pos := su3HeaderLen
+	f.Version = string(bytes.TrimRight(b[pos:pos+versionLen], "\x00"))
+	pos += versionLen
+	f.SignerID = string(b[pos : pos+signerLen])
+	pos += signerLen
+	f.Content = b[pos : pos+int(contentLen)]
+	pos += int(contentLen)
+	f.signed = b[:pos]
+	f.Signature = b[pos:]
+	return f, nil
+}
+
+// LoadSU3File reads and parses an su3 file.
+func LoadSU3File(path string) (*SU3File, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading su3 file: %w", err)
+	}
+	f, err := ReadSU3(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
+
+// signingPublicKey converts the raw I2P encoding of a signing public key.
+func signingPublicKey(t SigType, raw []byte) (crypto.PublicKey, error) {
+	if t.PublicKeyLen() == 0 || t == SigTypeDSASHA1 {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSigType, t)
+	}
+	if len(raw) != t.PublicKeyLen() {
+		return nil, fmt.Errorf("invalid %s public key length %d", t, len(raw))
+	}
+	if curve, _, ok := ecdsaParams(t); ok {
+		n := len(raw) / 2
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(raw[:n]), Y: new(big.Int).SetBytes(raw[n:])}, nil
+	}
+	if _, ok := rsaHash(t); ok {
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(raw), E: 65537}, nil
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// Verify checks the signature of the file with pub, which is an I2PAddr or
+// I2PKeys whose destination signed it, the raw I2P encoding of a public key
+// of the file's SigType, or an *rsa.PublicKey, *ecdsa.PublicKey or
+// ed25519.PublicKey such as those of the certificates shipped with the
+// router. Ed25519ph signatures are Ed25519 signatures of the SHA-512 digest
+// of the signed data, as made by the Java router.
+func (f *SU3File) Verify(pub crypto.PublicKey) error {
+	log.WithField("signer", f.SignerID).WithField("sigType", f.SigType).Debug("Verifying su3 file")
+	switch k := pub.(type) {
+	case I2PKeys:
+		return f.Verify(k.Address)
+	case I2PAddr:
+		kc, err := k.keysAndCert()
+		if err != nil {
+			return err
+		}
+		if kc.sigType == SigTypeEd25519 && f.SigType == SigTypeEd25519ph {
+			return f.Verify(ed25519.PublicKey(kc.signingKey))
+		}
+		if kc.sigType != f.SigType {
+			return fmt.Errorf("su3 file is signed with %s, the destination uses %s", f.SigType, kc.sigType)
+		}
+		return f.Verify(kc.signingKey)
+	case []byte:
+		key, err := signingPublicKey(f.SigType, k)
+		if err != nil {
+			return err
+		}
+		return f.Verify(key)
+	}
+
+	if len(f.Signature) != f.SigType.SignatureLen() {
+		return fmt.Errorf("invalid %s signature length %d", f.SigType, len(f.Signature))
+	}
+	switch k := pub.(type) {
+	case ed25519.PublicKey:
+		msg := f.signed
+		switch f.SigType {
+		case SigTypeEd25519ph:
+			d := sha512.Sum512(f.signed)
+			msg = d[:]
+		case SigTypeEd25519:
+		default:
+			return fmt.Errorf("su3 file is signed with %s, not with an Ed25519 key", f.SigType)
+		}
+		if len(k) != ed25519.PublicKeySize || !ed25519.Verify(k, msg, f.Signature) {
+			return ErrInvalidSignature
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		curve, h, ok := ecdsaParams(f.SigType)
+		if !ok || k.Curve.Params().Name != curve.Params().Name {
+			return fmt.Errorf("su3 file is signed with %s, not with a %s key", f.SigType, k.Curve.Params().Name)
+		}
+		n := len(f.Signature) / 2
+		r, s := new(big.Int).SetBytes(f.Signature[:n]), new(big.Int).SetBytes(f.Signature[n:])
+		if !ecdsa.Verify(k, digest(h, f.signed), r, s) {
+			return ErrInvalidSignature
+		}
+		return nil
+	case *rsa.PublicKey:
+		h, ok := rsaHash(f.SigType)
+		if !ok || k.Size() != f.SigType.SignatureLen() {
+			return fmt.Errorf("su3 file is signed with %s, not with a %d bit RSA key", f.SigType, k.N.BitLen())
+		}
+		if err := rsa.VerifyPKCS1v15(k, h, digest(h, f.signed), f.Signature); err != nil {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: cannot verify %s with a %T", ErrUnsupportedSigType, f.SigType, pub)
+}
+
+// SU3CertStore maps signer IDs to their public keys.
+type SU3CertStore map[string]crypto.PublicKey
+
+// LoadSU3CertStore loads the signer certificates below dir, laid out like
+// the router's certificates directory: PEM or DER files ending in .crt or
+// .pem, named after the signer ID with "@" written as "_at_", such as
+// reseed/zzz_at_mail.i2p.crt.
+func LoadSU3CertStore(dir string) (SU3CertStore, error) {
+	log.WithField("dir", dir).Debug("Loading su3 certificates")
+	store := SU3CertStore{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := filepath.Ext(path)
+		if d.IsDir() || ext != ".crt" && ext != ".pem" {
+			return nil
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if block, _ := pem.Decode(b); block != nil {
+			b = block.Bytes
+		}
+		cert, err := x509.ParseCertificate(b)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		signer := strings.ReplaceAll(strings.TrimSuffix(d.Name(), ext), "_at_", "@")
+		if old, ok := store[signer]; ok {
+			if k, ok := old.(interface{ Equal(crypto.PublicKey) bool }); !ok || !k.Equal(cert.PublicKey) {
+				return fmt.Errorf("%s: conflicting certificates for %s", path, signer)
+			}
+		}
+		store[signer] = cert.PublicKey
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error loading su3 certificates: %w", err)
+	}
+	return store, nil
+}
+
+// VerifyWithStore checks the signature of the file with the key of its
+// signer in store.
+func (f *SU3File) VerifyWithStore(store SU3CertStore) error {
+	pub, ok := store[f.SignerID]
+	if !ok {
+		return fmt.Errorf("no certificate for su3 signer %q", f.SignerID)
+	}
+	return f.Verify(pub)
+}
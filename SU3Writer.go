@@ -0,0 +1,69 @@
+package i2pkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// appendSU3Signed appends the header and content of f, the data covered by
+// its signature.
+func appendSU3Signed(b []byte, f *SU3File) ([]byte, error) {
+	if f.Version == "" || len(f.Version) > 0xff {
+		return nil, fmt.Errorf("su3 version must be 1 to 255 bytes, is %d", len(f.Version))
+	}
+	if f.SignerID == "" || len(f.SignerID) > 0xff {
+		return nil, fmt.Errorf("su3 signer ID must be 1 to 255 bytes, is %d", len(f.SignerID))
+	}
+	versionLen := max(len(f.Version), su3MinVersionLen)
+	b = append(b, su3Magic...)
+	b = append(b, 0, 0)
+	b = binary.BigEndian.AppendUint16(b, uint16(f.SigType))
+	b = binary.BigEndian.AppendUint16(b, uint16(f.SigType.SignatureLen()))
+	b = append(b, 0, byte(versionLen), 0, byte(len(f.SignerID)))
+	b = binary.BigEndian.AppendUint64(b, uint64(len(f.Content)))
+	b = append(b, 0, byte(f.FileType), 0, byte(f.ContentType))
+	b = append(b, make([]byte, su3HeaderLen-28)...)
+	b = append(b, f.Version...)
+	b = append(b, make([]byte, versionLen-len(f.Version))...)
+	b = append(b, f.SignerID...)
+	return append(b, f.Content...), nil
+}
+
+// SignSU3 builds and signs an su3 file from the Version, SignerID,
+// FileType, ContentType and Content of f. Ed25519 destinations sign with
+// Ed25519ph, the type routers accept for su3 files, ECDSA destinations
+// with their own type. SigType, Signature and the signed data are set on
+// f, so that f.Verify(k) succeeds; the result is accepted by ReadSU3.
+func (k I2PKeys) SignSU3(f *SU3File) ([]byte, error) {
+	log.WithField("signer", f.SignerID).WithField("version", f.Version).Debug("Signing su3 file")
+	p, err := k.privateKeyFile()
+	if err != nil {
+		return nil, err
+	}
+	if p.offline != nil {
+		return nil, errors.New("su3 files cannot be signed with offline keys")
+	}
+	t := p.dest.sigType
+	if t == SigTypeEd25519 {
+		t = SigTypeEd25519ph
+	} else if _, _, ok := ecdsaParams(t); !ok {
+		return nil, fmt.Errorf("%w: cannot sign su3 files with %s", ErrUnsupportedSigType, t)
+	}
+	f.SigType = t
+	signed, err := appendSU3Signed(nil, f)
+	if err != nil {
+		return nil, err
+	}
+	var sig []byte
+	if t == SigTypeEd25519ph {
+		d := sha512.Sum512(signed)
+		sig = ed25519.Sign(ed25519.NewKeyFromSeed(p.signingPrivateKey), d[:])
+	} else if sig, err = signMessage(t, p.signingPrivateKey, signed); err != nil {
+		return nil, err
+	}
+	f.Signature, f.signed = sig, signed
+	return append(signed, sig...), nil
+}
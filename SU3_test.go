@@ -0,0 +1,192 @@
+package i2pkeys
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testSU3File() *SU3File {
+	return &SU3File{
+		Version:     "1.2.3",
+		SignerID:    "me@example.i2p",
+		FileType:    SU3FileTypeZip,
+		ContentType: SU3ContentTypePlugin,
+		Content:     []byte("plugin zip contents"),
+	}
+}
+
+func Test_SU3(t *testing.T) {
+	edKeys, err := GenerateDestination(SigTypeEd25519, CryptoTypeX25519)
+	if err != nil {
+		t.Fatalf("GenerateDestination failed: %v", err)
+	}
+	ecKeys, err := GenerateDestination(SigTypeECDSASHA256P256, CryptoTypeX25519)
+	if err != nil {
+		t.Fatalf("GenerateDestination failed: %v", err)
+	}
+
+	t.Run("Ed25519 round trip", func(t *testing.T) {
+		b, err := edKeys.SignSU3(testSU3File())
+		if err != nil {
+			t.Fatalf("SignSU3 failed: %v", err)
+		}
+		f, err := ReadSU3(b)
+		if err != nil {
+			t.Fatalf("ReadSU3 failed: %v", err)
+		}
+		want := testSU3File()
+		if f.SigType != SigTypeEd25519ph || f.Version != want.Version || f.SignerID != want.SignerID ||
+			f.FileType != want.FileType || f.ContentType != want.ContentType || !bytes.Equal(f.Content, want.Content) {
+			t.Errorf("Unexpected su3 file %+v", f)
+		}
+		if err := f.Verify(edKeys); err != nil {
+			t.Errorf("Verify with keys failed: %v", err)
+		}
+		pub, _ := edKeys.Address.SigningPublicKey()
+		if err := f.Verify(ed25519.PublicKey(pub)); err != nil {
+			t.Errorf("Verify with ed25519.PublicKey failed: %v", err)
+		}
+		if err := f.Verify(ecKeys.Address); err == nil {
+			t.Error("Verify should have failed with another destination")
+		}
+		b[len(b)-f.SigType.SignatureLen()-1] ^= 1
+		tampered, err := ReadSU3(b)
+		if err != nil {
+			t.Fatalf("ReadSU3 failed: %v", err)
+		}
+		if err := tampered.Verify(edKeys.Address); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("Expected ErrInvalidSignature for modified content, got %v", err)
+		}
+	})
+
+	t.Run("ECDSA with raw key", func(t *testing.T) {
+		f := testSU3File()
+		b, err := ecKeys.SignSU3(f)
+		if err != nil {
+			t.Fatalf("SignSU3 failed: %v", err)
+		}
+		if err := f.Verify(ecKeys); err != nil {
+			t.Errorf("Verify of the signed file failed: %v", err)
+		}
+		read, err := ReadSU3(b)
+		if err != nil {
+			t.Fatalf("ReadSU3 failed: %v", err)
+		}
+		pub, _ := ecKeys.Address.SigningPublicKey()
+		if read.SigType != SigTypeECDSASHA256P256 {
+			t.Errorf("SigType = %s", read.SigType)
+		}
+		if err := read.Verify(pub); err != nil {
+			t.Errorf("Verify with raw key failed: %v", err)
+		}
+	})
+
+	t.Run("Certificate store", func(t *testing.T) {
+		b, err := ecKeys.SignSU3(testSU3File())
+		if err != nil {
+			t.Fatalf("SignSU3 failed: %v", err)
+		}
+		f, _ := ReadSU3(b)
+		raw, _ := ecKeys.Address.SigningPublicKey()
+		pub, _ := signingPublicKey(SigTypeECDSASHA256P256, raw)
+
+		ca, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "me@example.i2p"},
+			NotBefore:    time.Now(),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, pub, ca)
+		if err != nil {
+			t.Fatalf("CreateCertificate failed: %v", err)
+		}
+		dir := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(dir, "plugin"), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, "plugin", "me_at_example.i2p.crt")
+		if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		store, err := LoadSU3CertStore(dir)
+		if err != nil {
+			t.Fatalf("LoadSU3CertStore failed: %v", err)
+		}
+		if err := f.VerifyWithStore(store); err != nil {
+			t.Errorf("VerifyWithStore failed: %v", err)
+		}
+		f.SignerID = "other@example.i2p"
+		if err := f.VerifyWithStore(store); err == nil {
+			t.Error("VerifyWithStore should have failed for an unknown signer")
+		}
+	})
+
+	t.Run("RSA", func(t *testing.T) {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f := testSU3File()
+		f.SigType = SigTypeRSASHA2562048
+		signed, err := appendSU3Signed(nil, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d := sha256.Sum256(signed)
+		sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, d[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		read, err := ReadSU3(append(signed, sig...))
+		if err != nil {
+			t.Fatalf("ReadSU3 failed: %v", err)
+		}
+		if err := read.Verify(&key.PublicKey); err != nil {
+			t.Errorf("Verify with RSA key failed: %v", err)
+		}
+		if err := read.Verify(key.N.Bytes()); err != nil {
+			t.Errorf("Verify with raw RSA key failed: %v", err)
+		}
+		if err := read.Verify(edKeys.Address); err == nil {
+			t.Error("Verify should have failed with an Ed25519 destination")
+		}
+	})
+
+	t.Run("Malformed files", func(t *testing.T) {
+		b, _ := edKeys.SignSU3(testSU3File())
+		shortVersion := append([]byte{}, b...)
+		shortVersion[13] = 15
+		for name, bad := range map[string][]byte{
+			"Bad magic":     append([]byte("I2Psu4"), b[6:]...),
+			"Truncated":     b[:len(b)-1],
+			"Trailing data": append(append([]byte{}, b...), 0),
+			"Header only":   b[:su3HeaderLen],
+			"Short version": shortVersion,
+		} {
+			if _, err := ReadSU3(bad); err == nil {
+				t.Errorf("%s: ReadSU3 should have failed", name)
+			}
+		}
+		f := testSU3File()
+		f.SignerID = ""
+		if _, err := edKeys.SignSU3(f); err == nil {
+			t.Error("SignSU3 should have failed without a signer ID")
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package i2pkeys
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AddressMatchKind is the kind of an address found by FindAddresses.
+type AddressMatchKind int
+
+const (
+	MatchB32           AddressMatchKind = iota // a .b32.i2p address
+	MatchB33                                   // a blinded .b32.i2p address
+	MatchDestination                           // a base64 destination
+	MatchHostname                              // a .i2p hostname
+	MatchAddressHelper                         // an i2paddresshelper parameter
+)
+
+func (k AddressMatchKind) String() string {
+	switch k {
+	case MatchB32:
+		return "b32"
+	case MatchB33:
+		return "b33"
+	case MatchDestination:
+		return "destination"
+	case MatchHostname:
+		return "hostname"
+	case MatchAddressHelper:
+		return "addresshelper"
+	}
+	return "AddressMatchKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// AddressMatch is an address found in text. Start and End are byte offsets
+// of Text in the scanned text. The parsed fields are set according to Kind:
+// Hash for b32, destinations and address helpers, Blinded for b33, Address
+// for destinations and address helpers, and Hostname for hostnames and for
+// address helpers in URLs with a .i2p host.
+type AddressMatch struct {
+	Kind       AddressMatchKind
+	Start, End int
+	Text       string
+	Hash       I2PDestHash
+	Blinded    BlindedAddress
+	Address    I2PAddr
+	Hostname   string
+}
+
+var (
+	scanB32Re      = regexp.MustCompile(`(?i)[a-z2-7]{52,}\.b32\.i2p`)
+	scanHostnameRe = regexp.MustCompile(`(?i)(?:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?\.)+i2p`)
+	scanBase64Re   = regexp.MustCompile(`[A-Za-z0-9~-]{516,}={0,2}`)
+	scanHelperRe   = regexp.MustCompile(`(?i)(?:https?://([a-z0-9.-]+\.i2p)[^\s?#"'<>]*)?[?&]i2paddresshelper=([^&\s#"'<>]+)`)
+)
+
+func isHostnameChar(c byte) bool {
+	return c == '-' || c == '.' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// atBoundary reports whether text[start:end] is not part of a longer name.
+func atBoundary(text string, start, end int) bool {
+	if start > 0 && isHostnameChar(text[start-1]) {
+		return false
+	}
+	return end == len(text) || !isHostnameChar(text[end]) || text[end] == '.' && (end+1 == len(text) || !isHostnameChar(text[end+1]))
+}
+
+// FindAddresses scans free text, such as logs, chat exports or web pages,
+// for .b32.i2p names, b33 names, base64 destinations, .i2p hostnames and
+// i2paddresshelper parameters. Every match is validated with the parser for
+// its kind, invalid candidates are dropped. Matches are returned in order of
+// their position and do not overlap.
+func FindAddresses(text string) []AddressMatch {
+	var matches []AddressMatch
+	taken := func(start, end int) bool {
+		for _, m := range matches {
+			if start < m.End && end > m.Start {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, loc := range scanHelperRe.FindAllStringSubmatchIndex(text, -1) {
+		value, err := url.QueryUnescape(text[loc[4]:loc[5]])
+		if err != nil {
+			continue
+		}
+		a, err := ParseAddress(I2PAddr(value))
+		if err != nil {
+			continue
+		}
+		m := AddressMatch{Kind: MatchAddressHelper, Start: loc[0], End: loc[1], Text: text[loc[0]:loc[1]], Hash: a.DestHash(), Address: a.I2PAddr()}
+		if loc[2] >= 0 {
+			m.Hostname = strings.ToLower(text[loc[2]:loc[3]])
+		}
+		matches = append(matches, m)
+	}
+
+	for _, loc := range scanBase64Re.FindAllStringIndex(text, -1) {
+		if taken(loc[0], loc[1]) {
+			continue
+		}
+		a, err := ParseAddress(I2PAddr(text[loc[0]:loc[1]]))
+		if err != nil {
+			continue
+		}
+		matches = append(matches, AddressMatch{Kind: MatchDestination, Start: loc[0], End: loc[1], Text: text[loc[0]:loc[1]], Hash: a.DestHash(), Address: a.I2PAddr()})
+	}
+
+	for _, loc := range scanB32Re.FindAllStringIndex(text, -1) {
+		if taken(loc[0], loc[1]) || !atBoundary(text, loc[0], loc[1]) {
+			continue
+		}
+		s := text[loc[0]:loc[1]]
+		m := AddressMatch{Kind: MatchB32, Start: loc[0], End: loc[1], Text: s}
+		if len(s) == 52+len(".b32.i2p") {
+			if DiagnoseB32(s) != nil {
+				continue
+			}
+			h, err := DestHashFromString(strings.ToLower(s))
+			if err != nil {
+				continue
+			}
+			m.Hash = h
+		} else {
+			b, err := NewBlindedAddressFromString(s)
+			if err != nil {
+				continue
+			}
+			m.Kind, m.Blinded = MatchB33, b
+		}
+		matches = append(matches, m)
+	}
+
+	for _, loc := range scanHostnameRe.FindAllStringIndex(text, -1) {
+		if taken(loc[0], loc[1]) || !atBoundary(text, loc[0], loc[1]) {
+			continue
+		}
+		s := strings.ToLower(text[loc[0]:loc[1]])
+		if strings.HasSuffix(s, ".b32.i2p") || len(s) > 67 {
+			continue
+		}
+		matches = append(matches, AddressMatch{Kind: MatchHostname, Start: loc[0], End: loc[1], Text: text[loc[0]:loc[1]], Hostname: s})
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Start < matches[j].Start })
+	log.WithField("count", len(matches)).Debug("Found addresses in text")
+	return matches
+}
@@ -0,0 +1,55 @@
+package i2pkeys
+
+import (
+	"net/url"
+	"testing"
+)
+
+func Test_FindAddresses(t *testing.T) {
+	addr := I2PAddr(validI2PAddrB64)
+	b32 := addr.Base32()
+	b33, _ := NewBlindedAddress(addr, false, false)
+	text := "See http://stats.i2p/ or " + b32 + ", and the b33 " + b33.String() + ".\n" +
+		"dest: " + addr.Base64() + "\n" +
+		"jump: http://example.i2p/path?i2paddresshelper=" + url.QueryEscape(addr.Base64()) + "&x=1\n" +
+		"not addresses: example.i2p.net, aaaa.b32.i2p, " + b32[:50] + "xx.b32.i2p"
+
+	matches := FindAddresses(text)
+	want := []struct {
+		kind AddressMatchKind
+		text string
+	}{
+		{MatchHostname, "stats.i2p"},
+		{MatchB32, b32},
+		{MatchB33, b33.String()},
+		{MatchDestination, addr.Base64()},
+		{MatchAddressHelper, "http://example.i2p/path?i2paddresshelper=" + url.QueryEscape(addr.Base64())},
+	}
+	if len(matches) != len(want) {
+		for _, m := range matches {
+			t.Logf("%s %q", m.Kind, m.Text)
+		}
+		t.Fatalf("Found %d addresses, want %d", len(matches), len(want))
+	}
+	for i, w := range want {
+		m := matches[i]
+		if m.Kind != w.kind || m.Text != w.text || text[m.Start:m.End] != m.Text {
+			t.Errorf("Match %d: got %s %q, want %s %q", i, m.Kind, m.Text, w.kind, w.text)
+		}
+	}
+	if matches[1].Hash != addr.DestHash() || matches[3].Address != addr || matches[4].Address != addr {
+		t.Error("Parsed addresses do not match")
+	}
+	if matches[4].Hostname != "example.i2p" || matches[0].Hostname != "stats.i2p" {
+		t.Errorf("Unexpected hostnames %q and %q", matches[4].Hostname, matches[0].Hostname)
+	}
+	if string(matches[2].Blinded.PublicKey) != string(b33.PublicKey) {
+		t.Error("Blinded address does not match")
+	}
+
+	t.Run("Empty text", func(t *testing.T) {
+		if m := FindAddresses("nothing to see here"); len(m) != 0 {
+			t.Errorf("Unexpected matches %v", m)
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package i2pkeys
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"io"
+
+	"filippo.io/edwards25519"
+)
+
+const (
+	sealedBoxInfo     = "i2pkeys sealed box v1"
+	sealedBoxOverhead = 32 + 16 // ephemeral key and GCM tag
+)
+
+// x25519PublicKey returns the X25519 key of a destination: its encryption key
+// if that is X25519, else its Ed25519 signing key converted to Montgomery
+// form.
+func (kc keysAndCert) x25519PublicKey() (*ecdh.PublicKey, error) {
+	switch {
+	case kc.cryptoType == CryptoTypeX25519:
+		return ecdh.X25519().NewPublicKey(kc.cryptoKey)
+	case kc.sigType == SigTypeEd25519:
+		p, err := new(edwards25519.Point).SetBytes(kc.signingKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Ed25519 public key: %w", err)
+		}
+		return ecdh.X25519().NewPublicKey(p.BytesMontgomery())
+	}
+	return nil, fmt.Errorf("%w: %s destination with %s signing key has no X25519 key", ErrUnsupportedCryptoType, kc.cryptoType, kc.sigType)
+}
+
+// x25519PrivateKey is the private counterpart of keysAndCert.x25519PublicKey.
+func (p privateKeyFile) x25519PrivateKey() (*ecdh.PrivateKey, error) {
+	switch {
+	case p.dest.cryptoType == CryptoTypeX25519:
+		return ecdh.X25519().NewPrivateKey(p.privateKey)
+	case p.dest.sigType == SigTypeEd25519:
+		if p.offline != nil {
+			return nil, errors.New("keys with an offline signature do not hold the Ed25519 key")
+		}
+		// The Ed25519 scalar is the clamped first half of SHA-512(seed),
+		// X25519 does the clamping itself.
+		h := sha512.Sum512(p.signingPrivateKey)
+		defer wipe(h[:])
+		return ecdh.X25519().NewPrivateKey(h[:32])
+	}
+	return nil, fmt.Errorf("%w: %s destination with %s signing key has no X25519 key", ErrUnsupportedCryptoType, p.dest.cryptoType, p.dest.sigType)
+}
+
+// sealedBoxAEAD derives the AES-256-GCM key and nonce of a sealed box from
+// the X25519 shared secret and both public keys.
+func sealedBoxAEAD(shared, ephemeral, recipient []byte) (cipher.AEAD, []byte, error) {
+	salt := append(append([]byte{}, ephemeral...), recipient...)
+	km, err := hkdfSHA256(shared, salt, sealedBoxInfo, 32+12)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer wipe(km[:32])
+	block, err := aes.NewCipher(km[:32])
+	if err != nil {
+		return nil, nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+	return aead, km[32:], nil
+}
+
+// Encrypt seals msg for the destination to, so that only the holder of its
+// private keys can read it. The X25519 encryption key of the destination is
+// used, or its Ed25519 signing key if it has none. The result is an
+// ephemeral X25519 public key followed by the AES-256-GCM ciphertext, 48
+// bytes longer than msg. The sender stays anonymous; sign msg with
+// SignMessage if the recipient needs to know who sent it.
+func Encrypt(to I2PAddr, msg []byte) ([]byte, error) {
+	log.WithField("to", to.Base32()).Debug("Encrypting message")
+	kc, err := to.keysAndCert()
+	if err != nil {
+		return nil, err
+	}
+	recipient, err := kc.x25519PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("error generating key: %w", err)
+	}
+	shared, err := ephemeral.ECDH(recipient)
+	if err != nil {
+		return nil, fmt.Errorf("error in key agreement: %w", err)
+	}
+	defer wipe(shared)
+	epub := ephemeral.PublicKey().Bytes()
+	aead, nonce, err := sealedBoxAEAD(shared, epub, recipient.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(epub, nonce, msg, nil), nil
+}
+
+// Decrypt opens a message sealed for these keys with Encrypt. The rand and
+// opts arguments are ignored; they exist so that I2PKeys implements
+// crypto.Decrypter. Keys with an offline signature only hold the transient
+// signing key, so they can only decrypt with an X25519 encryption key.
+func (k I2PKeys) Decrypt(rand io.Reader, msg []byte, opts crypto.DecrypterOpts) ([]byte, error) {
+	log.WithField("addr", k.Address.Base32()).Debug("Decrypting message")
+	if len(msg) < sealedBoxOverhead {
+		return nil, errors.New("message too short")
+	}
+	p, err := k.privateKeyFile()
+	if err != nil {
+		return nil, err
+	}
+	priv, err := p.x25519PrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	epub, err := ecdh.X25519().NewPublicKey(msg[:32])
+	if err != nil {
+		return nil, fmt.Errorf("invalid ephemeral key: %w", err)
+	}
+	shared, err := priv.ECDH(epub)
+	if err != nil {
+		return nil, fmt.Errorf("error in key agreement: %w", err)
+	}
+	defer wipe(shared)
+	aead, nonce, err := sealedBoxAEAD(shared, msg[:32], priv.PublicKey().Bytes())
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, nonce, msg[32:], nil)
+	if err != nil {
+		return nil, errors.New("message authentication failed")
+	}
+	return plaintext, nil
+}
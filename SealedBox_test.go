@@ -0,0 +1,97 @@
+package i2pkeys
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"testing"
+	"time"
+)
+
+var _ crypto.Decrypter = I2PKeys{}
+
+func Test_SealedBox(t *testing.T) {
+	msg := []byte("tunnel.0.option.i2cp.leaseSetPrivKey=...")
+	types := []struct {
+		name       string
+		sigType    SigType
+		cryptoType CryptoType
+	}{
+		{"X25519 encryption key", SigTypeECDSASHA256P256, CryptoTypeX25519},
+		{"Converted Ed25519 key", SigTypeEd25519, CryptoTypeElGamal},
+	}
+	for _, tt := range types {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := GenerateDestination(tt.sigType, tt.cryptoType)
+			if err != nil {
+				t.Fatalf("GenerateDestination failed: %v", err)
+			}
+			box, err := Encrypt(keys.Addr(), msg)
+			if err != nil {
+				t.Fatalf("Encrypt failed: %v", err)
+			}
+			if len(box) != len(msg)+sealedBoxOverhead {
+				t.Errorf("Unexpected sealed box length %d", len(box))
+			}
+			got, err := keys.Decrypt(rand.Reader, box, nil)
+			if err != nil {
+				t.Fatalf("Decrypt failed: %v", err)
+			}
+			if !bytes.Equal(got, msg) {
+				t.Errorf("Decrypted %q, want %q", got, msg)
+			}
+			box[len(box)-1] ^= 1
+			if _, err := keys.Decrypt(rand.Reader, box, nil); err == nil {
+				t.Error("Decrypt should have failed for a modified message")
+			}
+		})
+	}
+
+	t.Run("Wrong recipient", func(t *testing.T) {
+		alice, _ := GenerateDestination(SigTypeEd25519, CryptoTypeX25519)
+		bob, _ := GenerateDestination(SigTypeEd25519, CryptoTypeX25519)
+		box, err := Encrypt(alice.Addr(), msg)
+		if err != nil {
+			t.Fatalf("Encrypt failed: %v", err)
+		}
+		if _, err := bob.Decrypt(nil, box, nil); err == nil {
+			t.Error("Decrypt should have failed for another recipient")
+		}
+	})
+
+	t.Run("Offline keys", func(t *testing.T) {
+		for _, cryptoType := range []CryptoType{CryptoTypeX25519, CryptoTypeElGamal} {
+			keys, err := GenerateDestination(SigTypeEd25519, cryptoType)
+			if err != nil {
+				t.Fatalf("GenerateDestination failed: %v", err)
+			}
+			offline, err := keys.NewOfflineKeys(SigTypeEd25519, time.Now().Add(time.Hour))
+			if err != nil {
+				t.Fatalf("NewOfflineKeys failed: %v", err)
+			}
+			box, err := Encrypt(keys.Addr(), msg)
+			if err != nil {
+				t.Fatalf("Encrypt failed: %v", err)
+			}
+			got, err := offline.Decrypt(nil, box, nil)
+			switch {
+			case cryptoType == CryptoTypeElGamal && err == nil:
+				t.Error("Decrypt should have failed without the Ed25519 key")
+			case cryptoType == CryptoTypeX25519 && err != nil:
+				t.Errorf("Decrypt with the X25519 key failed: %v", err)
+			case cryptoType == CryptoTypeX25519 && !bytes.Equal(got, msg):
+				t.Errorf("Decrypted %q, want %q", got, msg)
+			}
+		}
+	})
+
+	t.Run("Unsupported key types", func(t *testing.T) {
+		keys, err := GenerateDestination(SigTypeECDSASHA256P256, CryptoTypeElGamal)
+		if err != nil {
+			t.Fatalf("GenerateDestination failed: %v", err)
+		}
+		if _, err := Encrypt(keys.Addr(), msg); err == nil {
+			t.Error("Encrypt should have failed without an X25519 or Ed25519 key")
+		}
+	})
+}
@@ -0,0 +1,185 @@
+package i2pkeys
+
+import (
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// SeedSize is the length of the secret a destination is derived from by
+// NewKeysFromSeed.
+const SeedSize = 32
+
+// seedInfo is the HKDF context for destination derivation. Changing it
+// changes every derived address.
+const seedInfo = "i2pkeys destination v1"
+
+//go:embed wordlists/bip39-english.txt
+var bip39English string
+
+var (
+	bip39Words   = strings.Fields(bip39English)
+	bip39Indexes = func() map[string]int {
+		m := make(map[string]int, len(bip39Words))
+		for i, w := range bip39Words {
+			m[w] = i
+		}
+		return m
+	}()
+)
+
+// NewSeed returns a random seed for use with NewKeysFromSeed.
+func NewSeed() ([]byte, error) {
+	seed := make([]byte, SeedSize)
+	if _, err := rand.Read(seed); err != nil {
+		log.WithError(err).Error("Error generating seed")
+		return nil, fmt.Errorf("error generating seed: %w", err)
+	}
+	return seed, nil
+}
+
+// NewKeysFromSeed deterministically derives a destination from a 256-bit
+// seed. The destination uses an Ed25519 signing key and an X25519 encryption
+// key, and the same seed always produces the same keys and therefore the same
+// .b32.i2p address.
+func NewKeysFromSeed(seed []byte) (I2PKeys, error) {
+	log.Debug("Deriving keys from seed")
+	p, err := privateKeyFileFromSeed(seed)
+	if err != nil {
+		log.WithError(err).Error("Error deriving keys from seed")
+		return I2PKeys{}, err
+	}
+	k := p.keys()
+	log.WithField("addr", k.Address.Base32()).Debug("Derived keys from seed")
+	return k, nil
+}
+
+// NewAddrFromSeed returns the destination NewKeysFromSeed would derive from
+// seed, without returning any private keys.
+func NewAddrFromSeed(seed []byte) (I2PAddr, error) {
+	p, err := privateKeyFileFromSeed(seed)
+	if err != nil {
+		return I2PAddr(""), err
+	}
+	wipe(p.privateKey)
+	wipe(p.signingPrivateKey)
+	return I2PAddr(i2pB64enc.EncodeToString(p.dest.raw)), nil
+}
+
+func privateKeyFileFromSeed(seed []byte) (privateKeyFile, error) {
+	if len(seed) != SeedSize {
+		return privateKeyFile{}, fmt.Errorf("invalid seed length %d, want %d", len(seed), SeedSize)
+	}
+	material, err := hkdfSHA256(seed, nil, seedInfo, 3*32)
+	if err != nil {
+		return privateKeyFile{}, fmt.Errorf("error expanding seed: %w", err)
+	}
+	defer wipe(material)
+	signingKey := ed25519.NewKeyFromSeed(material[0:32])
+	defer wipe(signingKey)
+	encryptionKey, err := ecdh.X25519().NewPrivateKey(material[32:64])
+	if err != nil {
+		return privateKeyFile{}, fmt.Errorf("error deriving encryption key: %w", err)
+	}
+	padding := material[64:96]
+	raw, err := newKeysAndCert(CryptoTypeX25519, encryptionKey.PublicKey().Bytes(),
+		SigTypeEd25519, signingKey.Public().(ed25519.PublicKey), padding)
+	if err != nil {
+		return privateKeyFile{}, err
+	}
+	dest, err := readKeysAndCert(raw)
+	if err != nil {
+		return privateKeyFile{}, err
+	}
+	return privateKeyFile{
+		dest:              dest,
+		privateKey:        encryptionKey.Bytes(),
+		signingPrivateKey: signingKey.Seed(),
+	}, nil
+}
+
+// wipe overwrites secret key material which is no longer needed.
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// hkdfSHA256 derives n bytes from secret with HKDF-SHA256.
+func hkdfSHA256(secret, salt []byte, info string, n int) ([]byte, error) {
+	out := make([]byte, n)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, []byte(info)), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SeedToMnemonic encodes a seed as a BIP39 English word list. A 256-bit seed
+// gives 24 words, the last of which carries a checksum.
+func SeedToMnemonic(seed []byte) (string, error) {
+	if len(seed) == 0 || len(seed)%4 != 0 || len(seed) > 32 {
+		return "", fmt.Errorf("invalid seed length %d", len(seed))
+	}
+	sum := sha256.Sum256(seed)
+	checksumBits := len(seed) / 4
+	n := new(big.Int).SetBytes(seed)
+	n.Lsh(n, uint(checksumBits))
+	n.Or(n, big.NewInt(int64(sum[0]>>(8-checksumBits))))
+
+	count := (len(seed)*8 + checksumBits) / 11
+	words := make([]string, count)
+	mask := big.NewInt(2047)
+	for i := count - 1; i >= 0; i-- {
+		words[i] = bip39Words[new(big.Int).And(n, mask).Int64()]
+		n.Rsh(n, 11)
+	}
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicToSeed decodes a BIP39 English word list produced by
+// SeedToMnemonic back into the seed, verifying its checksum. Words are
+// matched case-insensitively and may be separated by any whitespace.
+func MnemonicToSeed(mnemonic string) ([]byte, error) {
+	words := strings.Fields(strings.ToLower(mnemonic))
+	if len(words) == 0 || len(words)%3 != 0 || len(words) > 24 {
+		return nil, fmt.Errorf("invalid mnemonic length %d words", len(words))
+	}
+	n := new(big.Int)
+	for i, w := range words {
+		idx, ok := bip39Indexes[w]
+		if !ok {
+			return nil, fmt.Errorf("word %d (%q) is not in the word list", i+1, w)
+		}
+		n.Lsh(n, 11)
+		n.Or(n, big.NewInt(int64(idx)))
+	}
+	checksumBits := len(words) * 11 / 33
+	checksum := new(big.Int).And(n, big.NewInt(int64(1)<<checksumBits-1)).Int64()
+	n.Rsh(n, uint(checksumBits))
+	seed := n.FillBytes(make([]byte, checksumBits*4))
+	sum := sha256.Sum256(seed)
+	if int64(sum[0]>>(8-checksumBits)) != checksum {
+		return nil, errors.New("mnemonic checksum mismatch")
+	}
+	return seed, nil
+}
+
+// NewKeysFromMnemonic derives a destination from a seed encoded with
+// SeedToMnemonic.
+func NewKeysFromMnemonic(mnemonic string) (I2PKeys, error) {
+	seed, err := MnemonicToSeed(mnemonic)
+	if err != nil {
+		log.WithError(err).Error("Error decoding mnemonic")
+		return I2PKeys{}, err
+	}
+	return NewKeysFromSeed(seed)
+}
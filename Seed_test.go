@@ -0,0 +1,140 @@
+package i2pkeys
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"strings"
+	"testing"
+)
+
+// seedKeysB32 pins the derivation, any change to it breaks recovery of
+// existing destinations.
+const seedKeysB32 = "ph3f25gv6kqnfqmnpw7ln26sp4bqamk2vrrikdq4te4qctsi6fwa.b32.i2p"
+
+func Test_NewKeysFromSeed(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x42}, SeedSize)
+
+	t.Run("Deterministic", func(t *testing.T) {
+		a, err := NewKeysFromSeed(seed)
+		if err != nil {
+			t.Fatalf("NewKeysFromSeed failed: %v", err)
+		}
+		b, err := NewKeysFromSeed(seed)
+		if err != nil {
+			t.Fatalf("NewKeysFromSeed failed: %v", err)
+		}
+		if a != b {
+			t.Error("Same seed produced different keys")
+		}
+		if a.Addr().Base32() != seedKeysB32 {
+			t.Errorf("Derived address changed. Got %s, want %s", a.Addr().Base32(), seedKeysB32)
+		}
+	})
+
+	t.Run("Usable keys", func(t *testing.T) {
+		k, err := NewKeysFromSeed(seed)
+		if err != nil {
+			t.Fatalf("NewKeysFromSeed failed: %v", err)
+		}
+		if _, err := NewI2PAddrFromString(k.Addr().Base64()); err != nil {
+			t.Errorf("Derived address is not valid: %v", err)
+		}
+		p, err := k.privateKeyFile()
+		if err != nil {
+			t.Fatalf("Derived keys do not parse: %v", err)
+		}
+		if p.dest.sigType != SigTypeEd25519 || p.dest.cryptoType != CryptoTypeX25519 {
+			t.Errorf("Wrong key types %s/%s", p.dest.sigType, p.dest.cryptoType)
+		}
+		pub := ed25519.NewKeyFromSeed(p.signingPrivateKey).Public().(ed25519.PublicKey)
+		if !bytes.Equal(pub, p.dest.signingKey) {
+			t.Error("Signing private key does not match destination")
+		}
+	})
+
+	t.Run("Different seeds", func(t *testing.T) {
+		other := bytes.Repeat([]byte{0x43}, SeedSize)
+		a, _ := NewKeysFromSeed(seed)
+		b, _ := NewKeysFromSeed(other)
+		if a.Addr() == b.Addr() {
+			t.Error("Different seeds produced the same address")
+		}
+	})
+
+	t.Run("Invalid seed", func(t *testing.T) {
+		if _, err := NewKeysFromSeed(seed[:16]); err == nil {
+			t.Error("NewKeysFromSeed should have failed for short seed")
+		}
+	})
+}
+
+func Test_Mnemonic(t *testing.T) {
+	vectors := []struct {
+		seed     []byte
+		mnemonic string
+	}{
+		{bytes.Repeat([]byte{0x00}, 32), strings.Repeat("abandon ", 23) + "art"},
+		{bytes.Repeat([]byte{0x7f}, 16), "legal winner thank year wave sausage worth useful legal winner thank yellow"},
+		{bytes.Repeat([]byte{0xff}, 32), strings.Repeat("zoo ", 23) + "vote"},
+	}
+
+	t.Run("BIP39 vectors", func(t *testing.T) {
+		for _, v := range vectors {
+			m, err := SeedToMnemonic(v.seed)
+			if err != nil {
+				t.Fatalf("SeedToMnemonic failed: %v", err)
+			}
+			if m != v.mnemonic {
+				t.Errorf("SeedToMnemonic mismatch. Got '%s', want '%s'", m, v.mnemonic)
+			}
+			seed, err := MnemonicToSeed(v.mnemonic)
+			if err != nil {
+				t.Fatalf("MnemonicToSeed failed: %v", err)
+			}
+			if !bytes.Equal(seed, v.seed) {
+				t.Errorf("MnemonicToSeed mismatch. Got %x, want %x", seed, v.seed)
+			}
+		}
+	})
+
+	t.Run("Tolerates case and whitespace", func(t *testing.T) {
+		seed, err := MnemonicToSeed("  LEGAL winner\tthank year\nwave sausage worth useful legal winner thank yellow ")
+		if err != nil {
+			t.Fatalf("MnemonicToSeed failed: %v", err)
+		}
+		if !bytes.Equal(seed, vectors[1].seed) {
+			t.Error("MnemonicToSeed returned the wrong seed")
+		}
+	})
+
+	t.Run("Bad checksum", func(t *testing.T) {
+		if _, err := MnemonicToSeed(strings.Repeat("abandon ", 24)); err == nil {
+			t.Error("MnemonicToSeed should have failed for bad checksum")
+		}
+	})
+
+	t.Run("Unknown word", func(t *testing.T) {
+		if _, err := MnemonicToSeed(strings.Repeat("abandon ", 23) + "i2p"); err == nil {
+			t.Error("MnemonicToSeed should have failed for unknown word")
+		}
+	})
+
+	t.Run("Keys from mnemonic", func(t *testing.T) {
+		seed, err := NewSeed()
+		if err != nil {
+			t.Fatalf("NewSeed failed: %v", err)
+		}
+		m, err := SeedToMnemonic(seed)
+		if err != nil {
+			t.Fatalf("SeedToMnemonic failed: %v", err)
+		}
+		a, err := NewKeysFromMnemonic(m)
+		if err != nil {
+			t.Fatalf("NewKeysFromMnemonic failed: %v", err)
+		}
+		b, _ := NewKeysFromSeed(seed)
+		if a != b {
+			t.Error("Mnemonic and seed produced different keys")
+		}
+	})
+}
@@ -0,0 +1,215 @@
+package i2pkeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// sharePrefix starts the text encoding of a Share.
+const sharePrefix = "i2pshare1"
+
+// Share is one part of an I2PKeys split with SplitKeys. Any Threshold shares
+// of the same split can be combined with CombineShares to recover the keys,
+// fewer shares reveal nothing about them.
+type Share struct {
+	Threshold int
+	Index     int         // the x coordinate of the share, 1 to 255
+	DestHash  I2PDestHash // the address the share belongs to
+	Data      []byte
+}
+
+// GF(2^8) arithmetic with the AES polynomial, using 3 as the generator.
+var gfExp, gfLog = func() (exp [510]byte, lg [256]byte) {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		exp[i] = x
+		exp[i+255] = x
+		lg[x] = byte(i)
+		// multiply by 3
+		hi := x & 0x80
+		x2 := x << 1
+		if hi != 0 {
+			x2 ^= 0x1b
+		}
+		x ^= x2
+	}
+	return
+}()
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+func gfDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+255-int(gfLog[b])]
+}
+
+// SplitKeys splits the private keys into n shares, any threshold of which
+// recover them. threshold must be at least 2 and at most n, and n at most 255.
+func SplitKeys(keys I2PKeys, n, threshold int) ([]Share, error) {
+	log.WithField("n", n).WithField("threshold", threshold).Debug("Splitting keys")
+	if threshold < 2 || threshold > n || n > 255 {
+		return nil, fmt.Errorf("invalid share parameters: %d of %d", threshold, n)
+	}
+	p, err := keys.privateKeyFile()
+	if err != nil {
+		log.WithError(err).Error("Error parsing keys")
+		return nil, err
+	}
+	secret := p.bytes()
+	defer wipe(secret)
+	hash := I2PAddr(i2pB64enc.EncodeToString(p.dest.raw)).DestHash()
+
+	shares := make([]Share, n)
+	for i := range shares {
+		shares[i] = Share{Threshold: threshold, Index: i + 1, DestHash: hash, Data: make([]byte, len(secret))}
+	}
+	coeffs := make([]byte, threshold)
+	defer wipe(coeffs)
+	for j, s := range secret {
+		coeffs[0] = s
+		if _, err := rand.Read(coeffs[1:]); err != nil {
+			return nil, fmt.Errorf("error generating coefficients: %w", err)
+		}
+		for i := range shares {
+			x := byte(shares[i].Index)
+			// Horner's method
+			var y byte
+			for c := threshold - 1; c >= 0; c-- {
+				y = gfMul(y, x) ^ coeffs[c]
+			}
+			shares[i].Data[j] = y
+		}
+	}
+	log.WithField("addr", hash.String()).Debug("Split keys into shares")
+	return shares, nil
+}
+
+// CombineShares recovers the keys from at least Threshold shares of a single
+// split. The result is checked against the address recorded in the shares.
+func CombineShares(shares []Share) (I2PKeys, error) {
+	log.WithField("shares", len(shares)).Debug("Combining shares")
+	if len(shares) == 0 {
+		return I2PKeys{}, errors.New("no shares")
+	}
+	first := shares[0]
+	if first.Threshold < 2 {
+		return I2PKeys{}, fmt.Errorf("invalid threshold %d", first.Threshold)
+	}
+	if len(shares) < first.Threshold {
+		return I2PKeys{}, fmt.Errorf("need %d shares, have %d", first.Threshold, len(shares))
+	}
+	seen := make(map[int]bool)
+	for _, s := range shares {
+		if s.Threshold != first.Threshold || s.DestHash != first.DestHash || len(s.Data) != len(first.Data) {
+			return I2PKeys{}, fmt.Errorf("share %d belongs to a different split", s.Index)
+		}
+		if s.Index < 1 || s.Index > 255 || seen[s.Index] {
+			return I2PKeys{}, fmt.Errorf("invalid or duplicate share index %d", s.Index)
+		}
+		seen[s.Index] = true
+	}
+	shares = shares[:first.Threshold]
+
+	secret := make([]byte, len(first.Data))
+	defer wipe(secret)
+	for i, si := range shares {
+		// Lagrange basis polynomial for share i evaluated at 0
+		basis := byte(1)
+		xi := byte(si.Index)
+		for j, sj := range shares {
+			if i == j {
+				continue
+			}
+			xj := byte(sj.Index)
+			basis = gfMul(basis, gfDiv(xj, xj^xi))
+		}
+		for k, y := range si.Data {
+			secret[k] ^= gfMul(y, basis)
+		}
+	}
+	p, err := readPrivateKeyFile(secret)
+	if err != nil {
+		log.WithError(err).Error("Recovered keys do not parse")
+		return I2PKeys{}, fmt.Errorf("shares do not combine to valid keys: %w", err)
+	}
+	k := p.keys()
+	if k.Address.DestHash() != first.DestHash {
+		return I2PKeys{}, errors.New("recovered keys do not match the address of the shares")
+	}
+	log.WithField("addr", first.DestHash.String()).Debug("Recovered keys from shares")
+	return k, nil
+}
+
+func (s Share) checksum() []byte {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s:%d:%d:", sharePrefix, s.Threshold, s.Index)
+	h.Write(s.DestHash[:])
+	h.Write(s.Data)
+	return h.Sum(nil)[:4]
+}
+
+// String returns the printable form of the share, a single line of the form
+//
+//	i2pshare1:<threshold>:<index>:<address>.b32.i2p:<data>:<checksum>
+//
+// where data is base32 and checksum covers all preceding fields.
+func (s Share) String() string {
+	data := strings.TrimRight(i2pB32enc.EncodeToString(s.Data), "=")
+	return strings.Join([]string{
+		sharePrefix,
+		strconv.Itoa(s.Threshold),
+		strconv.Itoa(s.Index),
+		s.DestHash.String(),
+		data,
+		hex.EncodeToString(s.checksum()),
+	}, ":")
+}
+
+// ParseShare parses the printable form of a share produced by Share.String.
+// Whitespace is ignored and the checksum is verified.
+func ParseShare(str string) (Share, error) {
+	str = strings.ToLower(strings.Join(strings.Fields(str), ""))
+	fields := strings.Split(str, ":")
+	if len(fields) != 6 || fields[0] != sharePrefix {
+		return Share{}, errors.New("not an i2pkeys share")
+	}
+	var s Share
+	var err error
+	if s.Threshold, err = strconv.Atoi(fields[1]); err != nil {
+		return Share{}, fmt.Errorf("invalid threshold: %w", err)
+	}
+	if s.Index, err = strconv.Atoi(fields[2]); err != nil {
+		return Share{}, fmt.Errorf("invalid index: %w", err)
+	}
+	if s.DestHash, err = DestHashFromString(fields[3]); err != nil {
+		return Share{}, fmt.Errorf("invalid address: %w", err)
+	}
+	data := fields[4]
+	if pad := len(data) % 8; pad != 0 {
+		data += strings.Repeat("=", 8-pad)
+	}
+	if s.Data, err = i2pB32enc.DecodeString(data); err != nil {
+		return Share{}, fmt.Errorf("invalid share data: %w", err)
+	}
+	sum, err := hex.DecodeString(fields[5])
+	if err != nil {
+		return Share{}, fmt.Errorf("invalid checksum: %w", err)
+	}
+	if subtle.ConstantTimeCompare(sum, s.checksum()) != 1 {
+		return Share{}, fmt.Errorf("checksum mismatch in share %d", s.Index)
+	}
+	return s, nil
+}
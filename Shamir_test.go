@@ -0,0 +1,84 @@
+package i2pkeys
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_SplitKeys(t *testing.T) {
+	keys, err := NewKeysFromSeed(bytes.Repeat([]byte{0x07}, SeedSize))
+	if err != nil {
+		t.Fatalf("NewKeysFromSeed failed: %v", err)
+	}
+	shares, err := SplitKeys(keys, 5, 3)
+	if err != nil {
+		t.Fatalf("SplitKeys failed: %v", err)
+	}
+
+	t.Run("Any threshold subset", func(t *testing.T) {
+		subsets := [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}}
+		for _, subset := range subsets {
+			var picked []Share
+			for _, i := range subset {
+				picked = append(picked, shares[i])
+			}
+			recovered, err := CombineShares(picked)
+			if err != nil {
+				t.Fatalf("CombineShares(%v) failed: %v", subset, err)
+			}
+			if recovered != keys {
+				t.Errorf("CombineShares(%v) recovered different keys", subset)
+			}
+		}
+	})
+
+	t.Run("Below threshold", func(t *testing.T) {
+		if _, err := CombineShares(shares[:2]); err == nil {
+			t.Error("CombineShares should have failed with too few shares")
+		}
+	})
+
+	t.Run("Duplicate share", func(t *testing.T) {
+		if _, err := CombineShares([]Share{shares[0], shares[0], shares[1]}); err == nil {
+			t.Error("CombineShares should have failed with duplicate shares")
+		}
+	})
+
+	t.Run("Corrupted share", func(t *testing.T) {
+		bad := shares[1]
+		bad.Data = append([]byte{}, bad.Data...)
+		bad.Data[0] ^= 1
+		if _, err := CombineShares([]Share{shares[0], bad, shares[2]}); err == nil {
+			t.Error("CombineShares should have failed with a corrupted share")
+		}
+	})
+
+	t.Run("Text encoding", func(t *testing.T) {
+		str := shares[3].String()
+		if !strings.Contains(str, keys.Addr().Base32()) {
+			t.Errorf("Share text does not name its address: %s", str)
+		}
+		parsed, err := ParseShare(" " + strings.ToUpper(str[:40]) + "\n" + str[40:] + "\n")
+		if err != nil {
+			t.Fatalf("ParseShare failed: %v", err)
+		}
+		if parsed.Index != shares[3].Index || !bytes.Equal(parsed.Data, shares[3].Data) {
+			t.Error("ParseShare returned a different share")
+		}
+		typo := []byte(str)
+		typo[len(typo)-20] ^= 0x01
+		if _, err := ParseShare(string(typo)); err == nil {
+			t.Error("ParseShare should have failed for a mistyped share")
+		}
+	})
+
+	t.Run("Invalid parameters", func(t *testing.T) {
+		if _, err := SplitKeys(keys, 2, 3); err == nil {
+			t.Error("SplitKeys should have failed with threshold above n")
+		}
+		if _, err := SplitKeys(keys, 3, 1); err == nil {
+			t.Error("SplitKeys should have failed with threshold 1")
+		}
+	})
+}
@@ -0,0 +1,248 @@
+package i2pkeys
+
+import (
+	"crypto"
+	"crypto/dsa"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// ErrUnsupportedSigType is returned when an operation is not implemented for
+// a signature type.
+var ErrUnsupportedSigType = errors.New("unsupported signature type")
+
+// ErrInvalidSignature is returned when a signature does not verify.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// dsaParameters is the fixed 1024 bit group of DSA_SHA1 signatures.
+var dsaParameters = func() dsa.Parameters {
+	p, _ := new(big.Int).SetString(
+		"9C05B2AA960D9B97B8931963C9CC9E8C3026E9B8ED92FAD0"+
+			"A69CC886D5BF8015FCADAE31A0AD18FAB3F01B00A358DE23"+
+			"7655C4964AFAA2B337E96AD316B9FB1CC564B5AEC5B69A9F"+
+			"F6C3E4548707FEF8503D91DD8602E867E6D35D2235C1869C"+
+			"E2479C3B9D5401DE04E0727FB33D6511285D4CF29538D9E3"+
+			"B6051F5B22CC1C93", 16)
+	q, _ := new(big.Int).SetString("A5DFC28FEF4CA1E286744CD8EED9D29D684046B7", 16)
+	g, _ := new(big.Int).SetString(
+		"0C1F4D27D40093B429E962D7223824E0BBC47E7C832A3923"+
+			"6FC683AF84889581075FF9082ED32353D4374D7301CDA1D2"+
+			"3C431F4698599DDA02451824FF369752593647CC3DDC197D"+
+			"E985E43D136CDCFC6BD5409CD2F450821142A5E6F8EB1C3A"+
+			"B5D0484B8129FCF17BCE4F7F33321C3CB3DBB14A905E7B2B"+
+			"3E93BE4708CBCC82", 16)
+	return dsa.Parameters{P: p, Q: q, G: g}
+}()
+
+func ecdsaParams(t SigType) (elliptic.Curve, crypto.Hash, bool) {
+	switch t {
+	case SigTypeECDSASHA256P256:
+		return elliptic.P256(), crypto.SHA256, true
+	case SigTypeECDSASHA384P384:
+		return elliptic.P384(), crypto.SHA384, true
+	case SigTypeECDSASHA512P521:
+		return elliptic.P521(), crypto.SHA512, true
+	}
+	return nil, 0, false
+}
+
+// ecdsaECDHCurve returns the crypto/ecdh curve of an ECDSA signature type.
+func ecdsaECDHCurve(t SigType) ecdh.Curve {
+	switch t {
+	case SigTypeECDSASHA256P256:
+		return ecdh.P256()
+	case SigTypeECDSASHA384P384:
+		return ecdh.P384()
+	}
+	return ecdh.P521()
+}
+
+// ecdsaPrivateKey converts the raw I2P encoding of an ECDSA private key. The
+// public point is computed with crypto/ecdh, which also rejects scalars out
+// of range.
+func ecdsaPrivateKey(t SigType, priv []byte) (*ecdsa.PrivateKey, error) {
+	curve, _, ok := ecdsaParams(t)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSigType, t)
+	}
+	k, err := ecdsaECDHCurve(t).NewPrivateKey(priv)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s private key: %w", t, err)
+	}
+	point := k.PublicKey().Bytes()[1:] // uncompressed X || Y
+	n := len(point) / 2
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(point[:n]), Y: new(big.Int).SetBytes(point[n:])},
+		D:         new(big.Int).SetBytes(priv),
+	}, nil
+}
+
+func rsaHash(t SigType) (crypto.Hash, bool) {
+	switch t {
+	case SigTypeRSASHA2562048:
+		return crypto.SHA256, true
+	case SigTypeRSASHA3843072:
+		return crypto.SHA384, true
+	case SigTypeRSASHA5124096:
+		return crypto.SHA512, true
+	}
+	return 0, false
+}
+
+func digest(h crypto.Hash, msg []byte) []byte {
+	switch h {
+	case crypto.SHA256:
+		d := sha256.Sum256(msg)
+		return d[:]
+	case crypto.SHA384:
+		d := sha512.Sum384(msg)
+		return d[:]
+	default:
+		d := sha512.Sum512(msg)
+		return d[:]
+	}
+}
+
+// VerifySignature checks a signature of type t over msg with the raw I2P
+// encoding of a signing public key. Ed25519ph is not supported.
+func VerifySignature(t SigType, pub, msg, sig []byte) error {
+	if t.PublicKeyLen() == 0 {
+		return fmt.Errorf("%w: %s", ErrUnsupportedSigType, t)
+	}
+	if len(pub) != t.PublicKeyLen() {
+		return fmt.Errorf("invalid %s public key length %d", t, len(pub))
+	}
+	if len(sig) != t.SignatureLen() {
+		return fmt.Errorf("invalid %s signature length %d", t, len(sig))
+	}
+	switch t {
+	case SigTypeDSASHA1:
+		key := &dsa.PublicKey{Parameters: dsaParameters, Y: new(big.Int).SetBytes(pub)}
+		r, s := new(big.Int).SetBytes(sig[:20]), new(big.Int).SetBytes(sig[20:])
+		d := sha1.Sum(msg)
+		if !dsa.Verify(key, d[:], r, s) {
+			return ErrInvalidSignature
+		}
+		return nil
+	case SigTypeEd25519, SigTypeRedDSAEd25519:
+		// RedDSA signatures verify exactly like EdDSA ones.
+		if !ed25519.Verify(ed25519.PublicKey(pub), msg, sig) {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+	if curve, h, ok := ecdsaParams(t); ok {
+		n := len(pub) / 2
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(pub[:n]), Y: new(big.Int).SetBytes(pub[n:])}
+		if _, err := ecdsaECDHCurve(t).NewPublicKey(append([]byte{4}, pub...)); err != nil {
+			return errors.New("public key is not on the curve")
+		}
+		n = len(sig) / 2
+		r, s := new(big.Int).SetBytes(sig[:n]), new(big.Int).SetBytes(sig[n:])
+		if !ecdsa.Verify(key, digest(h, msg), r, s) {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+	if h, ok := rsaHash(t); ok {
+		key := &rsa.PublicKey{N: new(big.Int).SetBytes(pub), E: 65537}
+		if err := rsa.VerifyPKCS1v15(key, h, digest(h, msg), sig); err != nil {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrUnsupportedSigType, t)
+}
+
+// signMessage signs msg with the raw I2P encoding of a signing private key.
+func signMessage(t SigType, priv, msg []byte) ([]byte, error) {
+	if len(priv) != t.PrivateKeyLen() || t.PrivateKeyLen() == 0 {
+		return nil, fmt.Errorf("invalid %s private key length %d", t, len(priv))
+	}
+	switch t {
+	case SigTypeEd25519:
+		return ed25519.Sign(ed25519.NewKeyFromSeed(priv), msg), nil
+	}
+	if _, h, ok := ecdsaParams(t); ok {
+		key, err := ecdsaPrivateKey(t, priv)
+		if err != nil {
+			return nil, err
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest(h, msg))
+		if err != nil {
+			return nil, fmt.Errorf("error signing: %w", err)
+		}
+		n := t.SignatureLen() / 2
+		sig := make([]byte, 2*n)
+		r.FillBytes(sig[:n])
+		s.FillBytes(sig[n:])
+		return sig, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedSigType, t)
+}
+
+// GenerateSigningKey creates a new signing key pair of type t and returns it
+// in raw I2P encoding. Ed25519 and the ECDSA types are supported.
+func GenerateSigningKey(t SigType) (pub, priv []byte, err error) {
+	switch t {
+	case SigTypeEd25519:
+		pk, sk, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error generating key: %w", err)
+		}
+		return pk, sk.Seed(), nil
+	}
+	if curve, _, ok := ecdsaParams(t); ok {
+		key, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error generating key: %w", err)
+		}
+		n := t.PublicKeyLen() / 2
+		pub = make([]byte, 2*n)
+		key.X.FillBytes(pub[:n])
+		key.Y.FillBytes(pub[n:])
+		priv = key.D.FillBytes(make([]byte, t.PrivateKeyLen()))
+		return pub, priv, nil
+	}
+	return nil, nil, fmt.Errorf("%w: %s", ErrUnsupportedSigType, t)
+}
+
+// signingKey returns the key which currently signs for the keys: the
+// transient key if the destination key is offline, else the destination key.
+func (p privateKeyFile) signingKey() (SigType, []byte) {
+	if p.offline != nil {
+		return p.offline.TransientType, p.offline.transientPrivateKey
+	}
+	return p.dest.sigType, p.signingPrivateKey
+}
+
+// SignMessage signs msg the way the router would sign on behalf of the
+// destination: with the transient key if the keys carry an offline
+// signature, else with the destination's signing key.
+func (k I2PKeys) SignMessage(msg []byte) ([]byte, error) {
+	p, err := k.privateKeyFile()
+	if err != nil {
+		return nil, err
+	}
+	t, priv := p.signingKey()
+	return signMessage(t, priv, msg)
+}
+
+// VerifyMessage checks a signature made by the destination's signing key.
+func (addr I2PAddr) VerifyMessage(msg, sig []byte) error {
+	kc, err := addr.keysAndCert()
+	if err != nil {
+		return err
+	}
+	return VerifySignature(kc.sigType, kc.signingKey, msg, sig)
+}
@@ -0,0 +1,97 @@
+package i2pkeys
+
+import (
+	"bytes"
+	"crypto/dsa"
+	"crypto/rand"
+	"crypto/sha1"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func Test_Signatures(t *testing.T) {
+	msg := []byte("hello i2p")
+	for _, sigType := range []SigType{SigTypeEd25519, SigTypeECDSASHA256P256, SigTypeECDSASHA384P384, SigTypeECDSASHA512P521} {
+		t.Run(sigType.String(), func(t *testing.T) {
+			pub, priv, err := GenerateSigningKey(sigType)
+			if err != nil {
+				t.Fatalf("GenerateSigningKey failed: %v", err)
+			}
+			if len(pub) != sigType.PublicKeyLen() || len(priv) != sigType.PrivateKeyLen() {
+				t.Fatalf("Wrong key lengths %d/%d", len(pub), len(priv))
+			}
+			sig, err := signMessage(sigType, priv, msg)
+			if err != nil {
+				t.Fatalf("signMessage failed: %v", err)
+			}
+			if err := VerifySignature(sigType, pub, msg, sig); err != nil {
+				t.Errorf("VerifySignature failed: %v", err)
+			}
+			if err := VerifySignature(sigType, pub, []byte("hello i2q"), sig); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("Expected ErrInvalidSignature for modified message, got %v", err)
+			}
+		})
+	}
+
+	t.Run("Destination", func(t *testing.T) {
+		keys, err := NewKeysFromSeed(bytes.Repeat([]byte{0x11}, SeedSize))
+		if err != nil {
+			t.Fatalf("NewKeysFromSeed failed: %v", err)
+		}
+		sig, err := keys.SignMessage(msg)
+		if err != nil {
+			t.Fatalf("SignMessage failed: %v", err)
+		}
+		if err := keys.Addr().VerifyMessage(msg, sig); err != nil {
+			t.Errorf("VerifyMessage failed: %v", err)
+		}
+	})
+
+	t.Run("DSA_SHA1", func(t *testing.T) {
+		// DSA keys are no longer generated, but legacy destinations,
+		// LeaseSets and RouterInfos still carry DSA signatures.
+		params := dsaParameters
+		if !params.P.ProbablyPrime(32) || !params.Q.ProbablyPrime(32) ||
+			new(big.Int).Mod(new(big.Int).Sub(params.P, big.NewInt(1)), params.Q).Sign() != 0 ||
+			new(big.Int).Exp(params.G, params.Q, params.P).Cmp(big.NewInt(1)) != 0 {
+			t.Fatal("Invalid DSA group")
+		}
+		var key dsa.PrivateKey
+		key.Parameters = params
+		if err := dsa.GenerateKey(&key, rand.Reader); err != nil {
+			t.Fatalf("GenerateKey failed: %v", err)
+		}
+		d := sha1.Sum(msg)
+		r, s, err := dsa.Sign(rand.Reader, &key, d[:])
+		if err != nil {
+			t.Fatalf("Sign failed: %v", err)
+		}
+		pub, sig := make([]byte, 128), make([]byte, 40)
+		key.Y.FillBytes(pub)
+		r.FillBytes(sig[:20])
+		s.FillBytes(sig[20:])
+		if err := VerifySignature(SigTypeDSASHA1, pub, msg, sig); err != nil {
+			t.Errorf("VerifySignature failed: %v", err)
+		}
+		if err := VerifySignature(SigTypeDSASHA1, pub, []byte("hello i2q"), sig); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("Expected ErrInvalidSignature for modified message, got %v", err)
+		}
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		if _, _, err := GenerateSigningKey(SigTypeDSASHA1); !errors.Is(err, ErrUnsupportedSigType) {
+			t.Errorf("Expected ErrUnsupportedSigType, got %v", err)
+		}
+	})
+
+	t.Run("Invalid ECDSA keys", func(t *testing.T) {
+		if _, err := signMessage(SigTypeECDSASHA256P256, bytes.Repeat([]byte{0xff}, 32), []byte("msg")); err == nil {
+			t.Error("signMessage should have failed for a scalar out of range")
+		}
+		pub := bytes.Repeat([]byte{1}, 64)
+		if err := VerifySignature(SigTypeECDSASHA256P256, pub, []byte("msg"), make([]byte, 64)); err == nil {
+			t.Error("VerifySignature should have failed for a point off the curve")
+		}
+	})
+}
@@ -0,0 +1,152 @@
+package i2pkeys
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const addressHelperParam = "i2paddresshelper"
+
+// Jump services commonly bundled with routers. JumpURL appends the escaped
+// hostname to them.
+const (
+	JumpServiceStats = "http://stats.i2p/cgi-bin/jump.cgi?a="
+	JumpServiceReg   = "http://reg.i2p/jump/"
+)
+
+var i2pHostnameRe = regexp.MustCompile(`^(?:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?\.)+i2p$`)
+
+// I2PURL is a parsed http(s) URL of an I2P site.
+type I2PURL struct {
+	URL           *url.URL    // the URL without the address helper parameter
+	Host          string      // the lowercase .i2p host, without port
+	Hash          I2PDestHash // set for .b32.i2p hosts and with an address helper
+	AddressHelper I2PAddr     // the destination of ?i2paddresshelper=, or empty
+}
+
+// ParseI2PURL parses an http or https URL whose host is a .i2p hostname or
+// .b32.i2p address. An i2paddresshelper parameter is validated as a
+// destination and, for .b32.i2p and blinded hosts, checked against the host.
+func ParseI2PURL(raw string) (*I2PURL, error) {
+	log.WithField("url", raw).Debug("Parsing I2P URL")
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+	p := &I2PURL{URL: u, Host: strings.ToLower(u.Hostname())}
+	var (
+		isB32   bool
+		blinded *BlindedAddress
+	)
+	switch {
+	case len(p.Host) == 52+len(".b32.i2p") && strings.HasSuffix(p.Host, ".b32.i2p"):
+		if p.Hash, err = DestHashFromString(p.Host); err != nil {
+			return nil, fmt.Errorf("invalid host: %w", err)
+		}
+		isB32 = true
+	case IsBlindedAddress(p.Host):
+		b, err := NewBlindedAddressFromString(p.Host)
+		if err != nil {
+			return nil, fmt.Errorf("invalid host: %w", err)
+		}
+		blinded = &b
+	case !i2pHostnameRe.MatchString(p.Host):
+		return nil, fmt.Errorf("%q is not an I2P host", p.Host)
+	}
+
+	helpers := u.Query()[addressHelperParam]
+	if len(helpers) > 1 {
+		return nil, errors.New("more than one address helper")
+	}
+	if len(helpers) == 1 && helpers[0] != "" {
+		a, err := ParseAddress(I2PAddr(helpers[0]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid address helper: %w", err)
+		}
+		if isB32 && p.Hash != a.DestHash() {
+			return nil, errors.New("address helper does not match the .b32.i2p host")
+		}
+		if blinded != nil {
+			key, err := a.I2PAddr().SigningPublicKey()
+			if err != nil || a.SigType() != blinded.SigType || !bytes.Equal(key, blinded.PublicKey) {
+				return nil, errors.New("address helper does not match the blinded .b32.i2p host")
+			}
+		}
+		p.AddressHelper, p.Hash = a.I2PAddr(), a.DestHash()
+		stripped := *u
+		stripped.RawQuery = removeQueryParam(u.RawQuery, addressHelperParam)
+		p.URL = &stripped
+	}
+	return p, nil
+}
+
+// removeQueryParam removes every name parameter from a raw query, leaving
+// the other parameters as they are.
+func removeQueryParam(rawQuery, name string) string {
+	var kept []string
+	for _, part := range strings.Split(rawQuery, "&") {
+		key, _, _ := strings.Cut(part, "=")
+		if k, err := url.QueryUnescape(key); err == nil && k == name {
+			continue
+		}
+		kept = append(kept, part)
+	}
+	return strings.Join(kept, "&")
+}
+
+// Canonical returns the URL with its host replaced by the .b32.i2p address
+// and without address helper, so that URLs of the same site compare equal.
+// It fails for hostnames without address helper, which need a lookup.
+func (p *I2PURL) Canonical() (string, error) {
+	if p.Hash == (I2PDestHash{}) {
+		return "", fmt.Errorf("the destination of %s is unknown", p.Host)
+	}
+	u := *p.URL
+	u.Host = p.Hash.String()
+	if port := p.URL.Port(); port != "" {
+		u.Host += ":" + port
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	return u.String(), nil
+}
+
+// AddressHelperURL returns an http link to path on hostname which teaches
+// the visitor's router that hostname resolves to addr. The hostname is
+// checked with ValidateHostname.
+func AddressHelperURL(hostname string, addr I2PAddr, path string) (string, error) {
+	hostname, err := ValidateHostname(hostname)
+	if err != nil {
+		return "", err
+	}
+	if _, err := ParseAddress(addr); err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	u := url.URL{Scheme: "http", Host: hostname, Path: path, RawQuery: url.Values{addressHelperParam: {addr.Base64()}}.Encode()}
+	return u.String(), nil
+}
+
+// JumpURL returns the link to look up hostname at a jump service, given as
+// the prefix the hostname is appended to, such as JumpServiceStats.
+func JumpURL(jumpService, hostname string) (string, error) {
+	hostname, err := ValidateHostname(hostname)
+	if err != nil {
+		return "", err
+	}
+	if _, err := ParseI2PURL(jumpService + hostname); err != nil {
+		return "", fmt.Errorf("invalid jump service: %w", err)
+	}
+	return jumpService + url.QueryEscape(hostname), nil
+}
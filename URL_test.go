@@ -0,0 +1,118 @@
+package i2pkeys
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_I2PURL(t *testing.T) {
+	addr := I2PAddr(validI2PAddrB64)
+	b32 := addr.Base32()
+
+	t.Run("Address helper link round trip", func(t *testing.T) {
+		link, err := AddressHelperURL("Example.i2p", addr, "wiki/Main Page")
+		if err != nil {
+			t.Fatalf("AddressHelperURL failed: %v", err)
+		}
+		if !strings.HasPrefix(link, "http://example.i2p/wiki/Main%20Page?i2paddresshelper=") {
+			t.Errorf("Unexpected link %s", link)
+		}
+		u, err := ParseI2PURL(link)
+		if err != nil {
+			t.Fatalf("ParseI2PURL failed: %v", err)
+		}
+		if u.Host != "example.i2p" || u.AddressHelper != addr || u.Hash != addr.DestHash() {
+			t.Errorf("Unexpected I2PURL %+v", u)
+		}
+		canonical, err := u.Canonical()
+		if err != nil {
+			t.Fatalf("Canonical failed: %v", err)
+		}
+		if canonical != "http://"+b32+"/wiki/Main%20Page" {
+			t.Errorf("Canonical() = %s", canonical)
+		}
+	})
+
+	t.Run("b32 host", func(t *testing.T) {
+		u, err := ParseI2PURL("HTTP://" + strings.ToUpper(b32) + ":8080?a=1")
+		if err != nil {
+			t.Fatalf("ParseI2PURL failed: %v", err)
+		}
+		canonical, _ := u.Canonical()
+		if canonical != "http://"+b32+":8080/?a=1" {
+			t.Errorf("Canonical() = %s", canonical)
+		}
+	})
+
+	t.Run("Mismatched address helper", func(t *testing.T) {
+		other := I2PDestHash{1}.String()
+		link := "http://" + other + "/?i2paddresshelper=" + addr.Base64()
+		if _, err := ParseI2PURL(link); err == nil {
+			t.Error("ParseI2PURL should have failed for a helper not matching the b32 host")
+		}
+	})
+
+	t.Run("Other parameters are kept", func(t *testing.T) {
+		raw := "http://" + b32 + "/search?z=2&i2paddresshelper=" + addr.Base64() + "&a=%41+b&a=1"
+		u, err := ParseI2PURL(raw)
+		if err != nil {
+			t.Fatalf("ParseI2PURL failed: %v", err)
+		}
+		if u.URL.RawQuery != "z=2&a=%41+b&a=1" {
+			t.Errorf("RawQuery = %q", u.URL.RawQuery)
+		}
+		dup := raw + "&i2paddresshelper=" + addr.Base64()
+		if _, err := ParseI2PURL(dup); err == nil {
+			t.Error("ParseI2PURL should have failed for two address helpers")
+		}
+	})
+
+	t.Run("Blinded host", func(t *testing.T) {
+		owner, _ := NewKeysFromSeed(bytes.Repeat([]byte{0x47}, SeedSize))
+		other, _ := NewKeysFromSeed(bytes.Repeat([]byte{0x48}, SeedSize))
+		b33, err := NewBlindedAddress(owner.Addr(), false, false)
+		if err != nil {
+			t.Fatalf("NewBlindedAddress failed: %v", err)
+		}
+		u, err := ParseI2PURL("http://" + b33.String() + "/?i2paddresshelper=" + owner.Addr().Base64())
+		if err != nil {
+			t.Fatalf("ParseI2PURL rejected the matching helper: %v", err)
+		}
+		if canonical, _ := u.Canonical(); canonical != "http://"+owner.Addr().Base32()+"/" {
+			t.Errorf("Canonical() = %s", canonical)
+		}
+		if _, err := ParseI2PURL("http://" + b33.String() + "/?i2paddresshelper=" + other.Addr().Base64()); err == nil {
+			t.Error("ParseI2PURL should have failed for a helper not matching the blinded host")
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, raw := range []string{
+			"http://example.com/",
+			"ftp://example.i2p/",
+			"http://example.i2p/?i2paddresshelper=AAAA",
+		} {
+			if _, err := ParseI2PURL(raw); err == nil {
+				t.Errorf("ParseI2PURL(%q) should have failed", raw)
+			}
+		}
+		u, _ := ParseI2PURL("http://example.i2p/")
+		if _, err := u.Canonical(); err == nil {
+			t.Error("Canonical should have failed without a known destination")
+		}
+	})
+
+	t.Run("Jump links", func(t *testing.T) {
+		link, err := JumpURL(JumpServiceStats, "example.i2p")
+		if err != nil || link != "http://stats.i2p/cgi-bin/jump.cgi?a=example.i2p" {
+			t.Errorf("JumpURL = %s, %v", link, err)
+		}
+		if link, err := JumpURL(JumpServiceReg, "Example.I2P"); err != nil || link != "http://reg.i2p/jump/example.i2p" {
+			t.Errorf("JumpURL = %s, %v", link, err)
+		}
+		if _, err := JumpURL(JumpServiceStats, "example.com"); err == nil {
+			t.Error("JumpURL should have failed for a clearnet host")
+		}
+	})
+}
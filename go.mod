@@ -1,8 +1,12 @@
 module github.com/eyedeekay/i2pkeys
 
-go 1.17
+go 1.22.0
 
 require (
-	github.com/sirupsen/logrus v1.9.3 // indirect
-	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
+	filippo.io/edwards25519 v1.1.0
+	github.com/cloudflare/circl v1.6.1
+	github.com/sirupsen/logrus v1.9.3
+	golang.org/x/crypto v0.14.0
 )
+
+require golang.org/x/sys v0.13.0 // indirect